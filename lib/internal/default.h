@@ -4,9 +4,11 @@
 #include <stdio.h>
 #include <string.h>
 
+#include "test.h"
 #include "types.h"
 #include "heap.h"
 #include "vector.h"
+#include "str.h"
 #include "promise.h"
 
 #include "Block.h"
@@ -1,6 +1,8 @@
 #ifndef VO_INTERNAL_HEAP
 #define VO_INTERNAL_HEAP
 
+// threads spawned by the program allocate too
+#define GC_THREADS
 #include "gc.h"
 
 #define malloc(size) GC_malloc(size)
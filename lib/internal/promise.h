@@ -3,16 +3,231 @@
 
 #include "heap.h"
 #include "vector.h"
+#include "str.h"
+#include "Block.h"
+#include <uv.h>
+
+// a promise is pending, resolved with val or rejected with reason, it settles once and later calls to resolve or reject do nothing
+enum { PROMISE_PENDING, PROMISE_RESOLVED, PROMISE_REJECTED };
+
+// every promise starts like BasicPromise, so listeners can be called without knowing the type of val
+// lock guards settling and adding listeners, so a promise can be resolved from any thread
+// deferred is set when the promise settled away from the loop and its listeners were queued
+typedef struct BasicPromise {
+    u8 state;
+    u8 deferred;
+    uv_mutex_t lock;
+    VECTOR_TYPE(void *) listeners;
+    str reason;
+} BasicPromise;
 
 #define PROMISE_TYPE(type)                       \
     struct {                                     \
         u8 state;                                \
+        u8 deferred;                             \
+        uv_mutex_t lock;                         \
         VECTOR_TYPE(void *) listeners;           \
+        str reason;                              \
         type val;                                \
     } *
 
-#define PROMISE_NEW(type) (void *)({PROMISE_TYPE(type) prom = malloc(sizeof(PROMISE_TYPE(type))); prom->listeners = VECTOR_NEW(void *); prom->state = 0; prom; })
-#define PROMISE_THEN(promise, callback) VECTOR_PUSH(promise->listeners, (void *)callback)
-#define PROMISE_RESOLVE(promise, value) ({ promise->val = value; promise->state = 1; VECTOR_FOREACH(promise->listeners, ({ ((void (^)(typeof(promise->val)))it)(promise->val); })); })
+void _promise_listen(BasicPromise *, void (^)(void));
+void _promise_settle(BasicPromise *, u8);
+
+#define PROMISE_NEW(type) (void *)({PROMISE_TYPE(type) prom = malloc(sizeof(*prom)); prom->listeners = VECTOR_NEW(void *); prom->state = PROMISE_PENDING; prom->deferred = 0; uv_mutex_init(&prom->lock); prom; })
+// the state can be read without the lock, val and reason are written before it
+#define PROMISE_STATE(promise) __atomic_load_n(&(promise)->state, __ATOMIC_ACQUIRE)
+// the value is evaluated before the lock is taken, it can be a call that settles other promises
+#define PROMISE_RESOLVE(promise, value) ({ __auto_type _res = promise; typeof(_res->val) _val = value; uv_mutex_lock(&_res->lock); \
+    if(_res->state == PROMISE_PENDING){ _res->val = _val; _promise_settle((BasicPromise *)_res, PROMISE_RESOLVED); } else { uv_mutex_unlock(&_res->lock); } })
+#define PROMISE_REJECT(promise, why) ({ __auto_type _rej = promise; str _why = why; uv_mutex_lock(&_rej->lock); \
+    if(_rej->state == PROMISE_PENDING){ _rej->reason = _why; _promise_settle((BasicPromise *)_rej, PROMISE_REJECTED); } else { uv_mutex_unlock(&_rej->lock); } })
+// PROMISE_ADOPT settles promise like from, which is settled
+#define PROMISE_ADOPT(promise, from) ({ __auto_type _from = from; if(PROMISE_STATE(_from) == PROMISE_RESOLVED){ PROMISE_RESOLVE(promise, _from->val); } else { PROMISE_REJECT(promise, _from->reason); } })
+
+// then with a callback that returns nothing returns the promise itself, rejections skip the callback
+#define PROMISE_THEN(promise, callback) ({ __auto_type _then = promise; void (^_cb)(typeof(_then->val)) = callback; _promise_listen((BasicPromise *)_then, ^{ if(PROMISE_STATE(_then) == PROMISE_RESOLVED) _cb(_then->val); }); _then; })
+// then with a callback that returns a value resolves a new promise with it, the new promise is rejected like the first one
+#define PROMISE_MAP(promise, callback) ({ __auto_type _map = promise; __auto_type _cb = callback; \
+    PROMISE_TYPE(typeof(_cb(_map->val))) _next = PROMISE_NEW(typeof(_cb(_map->val))); \
+    _promise_listen((BasicPromise *)_map, ^{ if(PROMISE_STATE(_map) == PROMISE_RESOLVED){ PROMISE_RESOLVE(_next, _cb(_map->val)); } else { PROMISE_REJECT(_next, _map->reason); } }); \
+    _next; })
+// then with a callback that returns a promise settles a new promise like the returned one
+#define PROMISE_FLAT(promise, callback) ({ __auto_type _flat = promise; __auto_type _cb = callback; \
+    typeof(_cb(_flat->val)) _next = PROMISE_NEW(typeof(_cb(_flat->val)->val)); \
+    _promise_listen((BasicPromise *)_flat, ^{ if(PROMISE_STATE(_flat) == PROMISE_RESOLVED){ typeof(_next) _inner = _cb(_flat->val); _promise_listen((BasicPromise *)_inner, ^{ PROMISE_ADOPT(_next, _inner); }); } else { PROMISE_REJECT(_next, _flat->reason); } }); \
+    _next; })
+#define PROMISE_CATCH(promise, callback) ({ __auto_type _catch = promise; void (^_cb)(str) = callback; _promise_listen((BasicPromise *)_catch, ^{ if(PROMISE_STATE(_catch) == PROMISE_REJECTED) _cb(_catch->reason); }); _catch; })
+#define PROMISE_FINALLY(promise, callback) ({ __auto_type _finally = promise; void (^_cb)(void) = callback; _promise_listen((BasicPromise *)_finally, ^{ _cb(); }); _finally; })
+
+// all resolves with the values of promises in their order once every one is resolved, it is rejected with the first rejection
+#define PROMISE_ALL(promises) ({ __auto_type _all = promises; size_t _n = _all->length; \
+    PROMISE_TYPE(VECTOR_TYPE(typeof(_all->mem[0]->val))) _next = PROMISE_NEW(VECTOR_TYPE(typeof(_all->mem[0]->val))); \
+    typeof(_next->val) _vals = VECTOR_NEW(typeof(_all->mem[0]->val)); \
+    if(_vals->capacity < _n){ VECTOR_RESIZE(_vals, _n - _vals->capacity); } \
+    _vals->length = _n; \
+    __block size_t _left = _n; \
+    if(_n == 0){ PROMISE_RESOLVE(_next, _vals); } \
+    for(size_t _i = 0; _i < _n; ++_i){ \
+        typeof(_all->mem[0]) _it = _all->mem[_i]; \
+        _promise_listen((BasicPromise *)_it, ^{ \
+            if(PROMISE_STATE(_it) == PROMISE_REJECTED){ PROMISE_REJECT(_next, _it->reason); return; } \
+            _vals->mem[_i] = _it->val; \
+            if(--_left == 0){ PROMISE_RESOLVE(_next, _vals); } \
+        }); \
+    } \
+    _next; })
+// race settles like the first of promises to settle, it stays pending when there are none
+#define PROMISE_RACE(promises) ({ __auto_type _race = promises; \
+    typeof(_race->mem[0]) _next = PROMISE_NEW(typeof(_race->mem[0]->val)); \
+    for(size_t _i = 0; _i < _race->length; ++_i){ \
+        typeof(_race->mem[0]) _it = _race->mem[_i]; \
+        _promise_listen((BasicPromise *)_it, ^{ PROMISE_ADOPT(_next, _it); }); \
+    } \
+    _next; })
+// any resolves like the first of promises to resolve, it is rejected with the last reason when every one is rejected
+#define PROMISE_ANY(promises) ({ __auto_type _any = promises; size_t _n = _any->length; \
+    typeof(_any->mem[0]) _next = PROMISE_NEW(typeof(_any->mem[0]->val)); \
+    __block size_t _left = _n; \
+    if(_n == 0){ PROMISE_REJECT(_next, STR_LITERAL(11, "no promises")); } \
+    for(size_t _i = 0; _i < _n; ++_i){ \
+        typeof(_any->mem[0]) _it = _any->mem[_i]; \
+        _promise_listen((BasicPromise *)_it, ^{ \
+            if(PROMISE_STATE(_it) == PROMISE_RESOLVED){ PROMISE_RESOLVE(_next, _it->val); } \
+            else if(--_left == 0){ PROMISE_REJECT(_next, _it->reason); } \
+        }); \
+    } \
+    _next; })
+
+// listeners always run on the thread of the default loop, a promise settled on another thread queues them and wakes
+// the loop with an async handle, the handle only keeps the loop alive while a thread holds it or listeners are queued
+static uv_async_t _promise_async;
+static uv_mutex_t _promise_lock;
+static uv_thread_t _promise_loop;
+static bool _promise_ready = 0;
+static VECTOR_TYPE(void *) _promise_queue;
+static size_t _promise_holds = 0;
+
+bool _promise_on_loop(void) {
+    uv_thread_t self = uv_thread_self();
+    return !_promise_ready || uv_thread_equal(&self, &_promise_loop);
+}
+
+// called on the loop thread, the loop waits for the async handle while there are holds or queued listeners
+void _promise_update(void) {
+    uv_mutex_lock(&_promise_lock);
+    bool wait = _promise_holds > 0 || _promise_queue->length > 0;
+    uv_mutex_unlock(&_promise_lock);
+    if(wait){
+        uv_ref((uv_handle_t *)&_promise_async);
+    } else {
+        uv_unref((uv_handle_t *)&_promise_async);
+    }
+}
+
+// listeners queued while the queue is run are run by the same call, in the order they were queued
+void _promise_dispatch(uv_async_t *async) {
+    for(;;){
+        uv_mutex_lock(&_promise_lock);
+        if(_promise_queue->length == 0){
+            uv_mutex_unlock(&_promise_lock);
+            break;
+        }
+        typeof(_promise_queue) queue = _promise_queue;
+        _promise_queue = VECTOR_NEW(void *);
+        uv_mutex_unlock(&_promise_lock);
+        for(size_t i = 0; i < queue->length; ++i){
+            void (^listener)(void) = queue->mem[i];
+            listener();
+            Block_release(listener);
+        }
+        VECTOR_FREE(queue);
+    }
+    _promise_update();
+}
+
+// _promise_init is called by main on the thread that runs the default loop, before any promise settles
+void _promise_init(void) {
+    _promise_loop = uv_thread_self();
+    uv_mutex_init(&_promise_lock);
+    _promise_queue = VECTOR_NEW(void *);
+    uv_async_init(uv_default_loop(), &_promise_async, _promise_dispatch);
+    uv_unref((uv_handle_t *)&_promise_async);
+    _promise_ready = 1;
+}
+
+// _promise_post queues a copied listener to be run and released on the loop thread
+void _promise_post(void (^listener)(void)) {
+    uv_mutex_lock(&_promise_lock);
+    VECTOR_PUSH(_promise_queue, (void *)listener);
+    uv_mutex_unlock(&_promise_lock);
+    uv_async_send(&_promise_async);
+    if(_promise_on_loop()){
+        _promise_update();
+    }
+}
+
+// a thread holds the loop while it can still settle promises, so the loop does not exit before their listeners ran
+void _promise_hold(void) {
+    uv_mutex_lock(&_promise_lock);
+    _promise_holds++;
+    uv_mutex_unlock(&_promise_lock);
+    if(_promise_on_loop()){
+        _promise_update();
+    } else {
+        uv_async_send(&_promise_async);
+    }
+}
+
+// a release from another thread is queued behind the listeners the thread queued before it
+void _promise_release(void) {
+    void (^release)(void) = ^{
+        uv_mutex_lock(&_promise_lock);
+        _promise_holds--;
+        uv_mutex_unlock(&_promise_lock);
+    };
+    if(_promise_on_loop()){
+        release();
+        _promise_update();
+    } else {
+        _promise_post(Block_copy(release));
+    }
+}
+
+// _promise_run calls a copied listener, right away on the loop thread and queued anywhere else
+void _promise_run(void (^listener)(void), bool defer) {
+    if(!defer && _promise_on_loop()){
+        listener();
+        Block_release(listener);
+        return;
+    }
+    _promise_post(listener);
+}
+
+// listeners are copied, a block literal lives on the stack of the function that made it
+// a listener added to a settled promise is run right away, unless listeners of the promise may still be queued
+void _promise_listen(BasicPromise *prom, void (^listener)(void)) {
+    uv_mutex_lock(&prom->lock);
+    if(prom->state == PROMISE_PENDING){
+        VECTOR_PUSH(prom->listeners, (void *)Block_copy(listener));
+        uv_mutex_unlock(&prom->lock);
+        return;
+    }
+    bool defer = prom->deferred;
+    uv_mutex_unlock(&prom->lock);
+    _promise_run(Block_copy(listener), defer);
+}
+
+// _promise_settle is called with the lock of prom held and releases it before the listeners run
+// listeners can add listeners to the promise while it is settling, they do not push since it is settled
+void _promise_settle(BasicPromise *prom, u8 state) {
+    prom->deferred = !_promise_on_loop();
+    __atomic_store_n(&prom->state, state, __ATOMIC_RELEASE);
+    uv_mutex_unlock(&prom->lock);
+    for(size_t i = 0; i < prom->listeners->length; ++i){
+        _promise_run(prom->listeners->mem[i], prom->deferred);
+    }
+    prom->listeners->length = 0;
+}
 
-#endif
\ No newline at end of file
+#endif
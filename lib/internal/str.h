@@ -0,0 +1,63 @@
+#ifndef VO_INTERNAL_STR
+#define VO_INTERNAL_STR
+
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "types.h"
+
+// str is an immutable view of bytes, it does not own them and they are not terminated by a NUL
+typedef struct str {
+    size_t length;
+    const u8 *mem;
+} str;
+
+str _str_from_cstring(const u8 *);
+str _str_slice(str, size_t, size_t);
+u8 *_str_cstring(str);
+int _str_compare(str, str);
+
+// literals are static data with their length in front of them, the str points to the bytes after the length
+#define STR_LITERAL(len, lit) ({ static const struct { size_t length; u8 mem[len + 1]; } _lit = {len, lit}; (str){_lit.length, _lit.mem}; })
+// compound literals outside of functions are static, so globals are initialized with one instead of a statement expression
+#define STR_GLOBAL(len, lit) ((str){len, ((const struct { size_t length; u8 mem[len + 1]; }){len, lit}).mem})
+
+#define STR_FROM_CSTRING(ptr) _str_from_cstring((const u8 *)(ptr))
+#define STR_FROM_BYTES(ptr, len) ((str){len, (const u8 *)(ptr)})
+#define STR_SLICE(s, start, end) _str_slice(s, start, end)
+#define STR_CSTRING(s) _str_cstring(s)
+#define STR_COMPARE(a, b) _str_compare(a, b)
+
+str _str_from_cstring(const u8 *ptr) {
+    if(ptr == NULL){
+        return (str){0, NULL};
+    }
+    return (str){strlen((const char *)ptr), ptr};
+}
+
+str _str_slice(str s, size_t start, size_t end) {
+    if(start > end || end > s.length){
+        fprintf(stderr, "slice [%zu:%zu] out of range for a str of length %zu\n", start, end, s.length);
+        abort();
+    }
+    return (str){end - start, s.mem + start};
+}
+
+u8 *_str_cstring(str s) {
+    u8 *buf = malloc(s.length + 1);
+    memcpy(buf, s.mem, s.length);
+    buf[s.length] = 0;
+    return buf;
+}
+
+int _str_compare(str a, str b) {
+    size_t n = a.length < b.length ? a.length : b.length;
+    int c = n == 0 ? 0 : memcmp(a.mem, b.mem, n);
+    if(c != 0){
+        return c;
+    }
+    return (a.length > b.length) - (a.length < b.length);
+}
+
+#endif
@@ -0,0 +1,59 @@
+#ifndef VO_INTERNAL_TEST
+#define VO_INTERNAL_TEST
+
+#include <setjmp.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef struct {
+	const char *name;
+	const char *file;
+	int line;
+	void (*run)(void);
+} VTest;
+
+// set while a test runs, failed assertions jump back to the runner instead of aborting
+static jmp_buf *v_test_env = NULL;
+
+static void v_assert_fail(const char *cond, const char *file, int line) {
+	fflush(stdout);
+	fprintf(stderr, "%s:%d: assertion failed: %s\n", file, line, cond);
+
+	if (v_test_env != NULL) {
+		longjmp(*v_test_env, 1);
+	}
+	abort();
+}
+
+#define v_assert(cond, text, file, line) ((cond) ? (void)0 : v_assert_fail(text, file, line))
+
+// v_test_main runs the tests whose name contains argv[1], or all of them, and returns 1 if any failed
+static int v_test_main(VTest *tests, int argc, char **argv) {
+	const char *filter = argc > 1 ? argv[1] : "";
+	int passed = 0, failed = 0;
+	jmp_buf env;
+
+	for (VTest *test = tests; test->name != NULL; test++) {
+		if (strstr(test->name, filter) == NULL) {
+			continue;
+		}
+
+		v_test_env = &env;
+		if (setjmp(env) == 0) {
+			test->run();
+			printf("ok   %s\n", test->name);
+			passed++;
+		} else {
+			printf("FAIL %s (%s:%d)\n", test->name, test->file, test->line);
+			failed++;
+		}
+		v_test_env = NULL;
+		fflush(stdout);
+	}
+
+	printf("%d passed, %d failed\n", passed, failed);
+	return failed > 0;
+}
+
+#endif
@@ -3,15 +3,17 @@
 
 #include "heap.h"
 
+// the elements are kept apart from the header, growing the vector moves them and not the header,
+// so every copy of the pointer to a vector, like the ones captured by blocks, sees the pushes
 typedef struct BasicVector {
     size_t length;
     size_t capacity;
-    char mem[];
+    char *mem;
 } BasicVector;
 
 BasicVector *_vector_new(size_t);
 BasicVector *_vector_copy(BasicVector *, char *, size_t, size_t);
-BasicVector *_vector_resize(BasicVector *, size_t, size_t);
+void _vector_resize(BasicVector *, size_t, size_t);
 BasicVector *_vector_concat(BasicVector *, BasicVector *, size_t);
 void _vector_free(BasicVector *);
 
@@ -19,16 +21,16 @@ void _vector_free(BasicVector *);
     struct {                \
         size_t length;      \
         size_t capacity;    \
-        type mem[];         \
+        type *mem;          \
     } *  
 
 #define VECTOR_NEW(type) (void *)_vector_new(sizeof(type))
-#define VECTOR_RESIZE(vector, num) _vector_resize((BasicVector*)vector, sizeof(BasicVector) + (num + vector->capacity), sizeof(*vector->mem)) 
+#define VECTOR_RESIZE(vector, num) _vector_resize((BasicVector*)vector, vector->capacity + num, sizeof(*vector->mem))
 #define VECTOR_COPY(vector, val, len) (void *)_vector_copy((BasicVector *)vector, val, len, sizeof(*vector->mem))
 
 #define VECTOR_PUSH(vector, value)              \
     ({ if(vector->length == vector->capacity){  \
-        VECTOR_RESIZE(vector, 8);               \
+        VECTOR_RESIZE(vector, vector->capacity);\
     }                                           \
     vector->mem[vector->length++] = value; })     
 
@@ -44,7 +46,8 @@ void _vector_free(BasicVector *);
     }
 
 BasicVector *_vector_new(size_t size_of_each_element){
-    BasicVector *vector = malloc(sizeof(BasicVector)+size_of_each_element*8);
+    BasicVector *vector = malloc(sizeof(BasicVector));
+    vector->mem = malloc(size_of_each_element*8);
     vector->length = 0;
     vector->capacity = 8;
     return vector;
@@ -62,10 +65,9 @@ BasicVector *_vector_copy(BasicVector *vec, char *mem, size_t len, size_t el_siz
     return vec;
 }
 
-BasicVector *_vector_resize(BasicVector *vector, size_t new_length, size_t el_size){
-    vector = realloc(vector, sizeof(BasicVector)+new_length*el_size);
+void _vector_resize(BasicVector *vector, size_t new_length, size_t el_size){
+    vector->mem = realloc(vector->mem, new_length*el_size);
     vector->capacity = new_length;
-    return vector;
 }
 
 BasicVector *_vector_concat(BasicVector *first, BasicVector *second, size_t el_size) {
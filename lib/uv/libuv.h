@@ -27,4 +27,207 @@ void _uv_check_cb(uv_check_t *check) {
     ((void (^)(void *))data->internal)(data->self);
 };
 
+// the data of a fs request is a copied block that is called once, when the request is done
+void _uv_fs_cb(uv_fs_t *req) {
+    void (^done)(uv_fs_t *) = (void (^)(uv_fs_t *))uv_req_get_data((uv_req_t *)req);
+    done(req);
+    Block_release(done);
+    uv_fs_req_cleanup(req);
+    free(req);
+};
+
+void _uv_alloc_cb(uv_handle_t *handle, size_t size, uv_buf_t *buf) {
+    buf->base = malloc(size);
+    buf->len = size;
+};
+
+// the read callback gets the number of bytes read or an error code, the bytes are freed after it returns
+void _uv_read_cb(uv_stream_t *stream, ssize_t nread, const uv_buf_t *buf) {
+    struct HandleData *data = (struct HandleData *)uv_handle_get_data((uv_handle_t *)stream);
+    if(nread != 0){
+        ((void (^)(void *, ssize_t, char *))data->internal)(data->self, nread, buf->base);
+    }
+    free(buf->base);
+};
+
+void _uv_connection_cb(uv_stream_t *server, int status) {
+    struct HandleData *data = (struct HandleData *)uv_handle_get_data((uv_handle_t *)server);
+    ((void (^)(void *, int))data->internal)(data->self, status);
+};
+
+// requests own a copy of their callback and are freed after calling it
+
+void _uv_req_cb(uv_req_t *req, int status) {
+    void (^done)(int) = (void (^)(int))uv_req_get_data(req);
+    done(status);
+    Block_release(done);
+    free(req);
+};
+
+void _uv_write_cb(uv_write_t *req, int status) {
+    _uv_req_cb((uv_req_t *)req, status);
+};
+
+void _uv_connect_cb(uv_connect_t *req, int status) {
+    _uv_req_cb((uv_req_t *)req, status);
+};
+
+void _uv_shutdown_cb(uv_shutdown_t *req, int status) {
+    _uv_req_cb((uv_req_t *)req, status);
+};
+
+void _uv_udp_send_cb(uv_udp_send_t *req, int status) {
+    _uv_req_cb((uv_req_t *)req, status);
+};
+
+// the bytes are copied behind the request, so they only have to be valid during the call
+int _uv_write(uv_stream_t *stream, const char *bytes, size_t length, void *done) {
+    uv_write_t *req = malloc(sizeof(uv_write_t) + length);
+    memcpy((char *)(req + 1), bytes, length);
+    uv_buf_t buf = uv_buf_init((char *)(req + 1), length);
+    uv_req_set_data((uv_req_t *)req, Block_copy(done));
+    int r = uv_write(req, stream, &buf, 1, _uv_write_cb);
+    if(r < 0){
+        Block_release(uv_req_get_data((uv_req_t *)req));
+        free(req);
+    }
+    return r;
+};
+
+int _uv_shutdown(uv_stream_t *stream, void *done) {
+    uv_shutdown_t *req = malloc(sizeof(uv_shutdown_t));
+    uv_req_set_data((uv_req_t *)req, Block_copy(done));
+    int r = uv_shutdown(req, stream, _uv_shutdown_cb);
+    if(r < 0){
+        Block_release(uv_req_get_data((uv_req_t *)req));
+        free(req);
+    }
+    return r;
+};
+
+// addresses are given as a host, an IPv4 or IPv6 address, and a port
+int _uv_addr(const char *host, int port, struct sockaddr_storage *addr) {
+    if(uv_ip4_addr(host, port, (struct sockaddr_in *)addr) == 0){
+        return 0;
+    }
+    return uv_ip6_addr(host, port, (struct sockaddr_in6 *)addr);
+};
+
+// _uv_addr_name writes the host of addr to host and returns its port
+int _uv_addr_name(const struct sockaddr *addr, char *host, size_t size) {
+    if(addr->sa_family == AF_INET6){
+        uv_ip6_name((const struct sockaddr_in6 *)addr, host, size);
+        return ntohs(((const struct sockaddr_in6 *)addr)->sin6_port);
+    }
+    uv_ip4_name((const struct sockaddr_in *)addr, host, size);
+    return ntohs(((const struct sockaddr_in *)addr)->sin_port);
+};
+
+int _uv_tcp_bind(uv_tcp_t *tcp, const char *host, int port) {
+    struct sockaddr_storage addr;
+    int r = _uv_addr(host, port, &addr);
+    if(r < 0){
+        return r;
+    }
+    return uv_tcp_bind(tcp, (const struct sockaddr *)&addr, 0);
+};
+
+int _uv_tcp_connect(uv_tcp_t *tcp, const char *host, int port, void *done) {
+    struct sockaddr_storage addr;
+    int r = _uv_addr(host, port, &addr);
+    if(r < 0){
+        return r;
+    }
+    uv_connect_t *req = malloc(sizeof(uv_connect_t));
+    uv_req_set_data((uv_req_t *)req, Block_copy(done));
+    r = uv_tcp_connect(req, tcp, (const struct sockaddr *)&addr, _uv_connect_cb);
+    if(r < 0){
+        Block_release(uv_req_get_data((uv_req_t *)req));
+        free(req);
+    }
+    return r;
+};
+
+// _uv_tcp_port returns the local port of tcp, or an error code
+int _uv_tcp_port(uv_tcp_t *tcp) {
+    struct sockaddr_storage addr;
+    char host[INET6_ADDRSTRLEN];
+    int size = sizeof(addr);
+    int r = uv_tcp_getsockname(tcp, (struct sockaddr *)&addr, &size);
+    if(r < 0){
+        return r;
+    }
+    return _uv_addr_name((const struct sockaddr *)&addr, host, sizeof(host));
+};
+
+int _uv_udp_bind(uv_udp_t *udp, const char *host, int port) {
+    struct sockaddr_storage addr;
+    int r = _uv_addr(host, port, &addr);
+    if(r < 0){
+        return r;
+    }
+    return uv_udp_bind(udp, (const struct sockaddr *)&addr, 0);
+};
+
+int _uv_udp_port(uv_udp_t *udp) {
+    struct sockaddr_storage addr;
+    char host[INET6_ADDRSTRLEN];
+    int size = sizeof(addr);
+    int r = uv_udp_getsockname(udp, (struct sockaddr *)&addr, &size);
+    if(r < 0){
+        return r;
+    }
+    return _uv_addr_name((const struct sockaddr *)&addr, host, sizeof(host));
+};
+
+int _uv_udp_send(uv_udp_t *udp, const char *host, int port, const char *bytes, size_t length, void *done) {
+    struct sockaddr_storage addr;
+    int r = _uv_addr(host, port, &addr);
+    if(r < 0){
+        return r;
+    }
+    uv_udp_send_t *req = malloc(sizeof(uv_udp_send_t) + length);
+    memcpy((char *)(req + 1), bytes, length);
+    uv_buf_t buf = uv_buf_init((char *)(req + 1), length);
+    uv_req_set_data((uv_req_t *)req, Block_copy(done));
+    r = uv_udp_send(req, udp, &buf, 1, (const struct sockaddr *)&addr, _uv_udp_send_cb);
+    if(r < 0){
+        Block_release(uv_req_get_data((uv_req_t *)req));
+        free(req);
+    }
+    return r;
+};
+
+// the receive callback also gets the host and port of the sender, the host is only valid during the call
+void _uv_udp_recv_cb(uv_udp_t *udp, ssize_t nread, const uv_buf_t *buf, const struct sockaddr *addr, unsigned flags) {
+    struct HandleData *data = (struct HandleData *)uv_handle_get_data((uv_handle_t *)udp);
+    if(nread < 0 || addr != NULL){
+        char host[INET6_ADDRSTRLEN] = "";
+        int port = addr == NULL ? 0 : _uv_addr_name(addr, host, sizeof(host));
+        ((void (^)(void *, ssize_t, char *, char *, int))data->internal)(data->self, nread, buf->base, host, port);
+    }
+    free(buf->base);
+};
+
+// a thread runs a copied block and releases it when the block returns
+// it is registered with the collector while it runs, since the block can allocate
+void _uv_thread_cb(void *run) {
+    struct GC_stack_base base;
+    GC_get_stack_base(&base);
+    GC_register_my_thread(&base);
+    ((void (^)(void))run)();
+    Block_release(run);
+    GC_unregister_my_thread();
+};
+
+int _uv_thread_create(uv_thread_t *thread, void *run) {
+    GC_allow_register_threads();
+    void *copy = Block_copy(run);
+    int r = uv_thread_create(thread, _uv_thread_cb, copy);
+    if(r < 0){
+        Block_release(copy);
+    }
+    return r;
+};
+
 #endif
\ No newline at end of file
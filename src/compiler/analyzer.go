@@ -22,8 +22,8 @@ func AnalyzeFile(ast File, pathh string) (*SymbolTable, map[string]*SymbolTable,
 	n := num
 	num++
 	s := SemanticAnalyzer{
-		Symbols:        &SymbolTable{},
-		Exports:        &SymbolTable{},
+		Symbols:        &SymbolTable{Path: pathh},
+		Exports:        &SymbolTable{Path: pathh},
 		Imports:        map[string]*SymbolTable{},
 		ImportPrefixes: map[string][]byte{},
 		Path:           pathh,
@@ -47,9 +47,10 @@ func AnalyzeFile(ast File, pathh string) (*SymbolTable, map[string]*SymbolTable,
 	s.addSymbol(VoidToken, VoidType)
 	s.addSymbol(SizeTToken, SizeTType)
 	s.addSymbol(BoolToken, BoolType)
+	s.addSymbol(StrToken, StrType)
 
-	s.addSymbol(True.Value, BoolType.Type)
-	s.addSymbol(False.Value, BoolType.Type)
+	s.addSymbol(True.Value, BasicType{Expr: IdentExpr{Value: BoolToken}})
+	s.addSymbol(False.Value, BasicType{Expr: IdentExpr{Value: BoolToken}})
 	s.addSymbol(Null.Value, VoidType.Type)
 
 	for _, statement := range ast.Statements {
@@ -59,7 +60,7 @@ func AnalyzeFile(ast File, pathh string) (*SymbolTable, map[string]*SymbolTable,
 }
 
 func (s *SemanticAnalyzer) error(message string, line, column int) {
-	error.New(s.Path+": "+message, line, column)
+	error.NewFileError(s.Path, message, line, column)
 }
 
 func (s *SemanticAnalyzer) getSymbol(Ident Token, Curr bool) (Node, bool) {
@@ -71,6 +72,7 @@ func (s *SemanticAnalyzer) getSymbol(Ident Token, Curr bool) (Node, bool) {
 
 func (s *SemanticAnalyzer) addSymbol(Ident Token, Type Type) {
 	s.Symbols.Add(Node{Identifier: Ident, Type: Type})
+	s.use(Ident, s.declPos(Ident), Type)
 }
 
 func (s *SemanticAnalyzer) pushScope() {
@@ -99,6 +101,8 @@ func (s *SemanticAnalyzer) globalStmt(stmt Statement) {
 		default:
 			s.error("Invalid export statement, expected typedef or decleration, got {st}", st.LineM(), st.ColumnM())
 		}
+	case Test:
+		s.test(stmt.(Test))
 	case NullStatement:
 		break
 	default:
@@ -130,6 +134,8 @@ func (s *SemanticAnalyzer) stmt(stmt Statement, returnType Type) {
 		s.expr(stmt.(Expression))
 	case Return:
 		s.rturn(stmt.(Return), returnType)
+	case Assert:
+		s.expr(stmt.(Assert).Cond)
 	}
 }
 
@@ -177,12 +183,14 @@ func (s *SemanticAnalyzer) declaration(dec Declaration) {
 		Type := dec.Types[0]
 
 		if len(dec.Values) == 0 {
-			Types = append(Types, Type)
+			for range dec.Identifiers {
+				Types = append(Types, Type)
+			}
 		}
 		for _, val := range dec.Values {
 			Types = append(Types, Type)
 			Type2 := s.getType(val)
-			if s.compareTypes(Type2, Type) {
+			if s.compareTypes(Type2, Type) || isStringLiteral(val) && isStr(Type, s.getType) {
 				continue
 			}
 			s.error("Type mismatch: val has type {Type2}, expected {Type}.", val.LineM(), val.ColumnM())
@@ -196,10 +204,12 @@ func (s *SemanticAnalyzer) declaration(dec Declaration) {
 
 			switch Typ.(type) {
 			case NumberType:
-				if bytes.Contains(val.(BasicLit).Value.Buff, []byte(".")) {
-					Typ = F32Type
-				} else {
-					Typ = I32Type.Type
+				Typ = I32Type.Type
+				switch val.(type) {
+				case BasicLit:
+					if bytes.Contains(val.(BasicLit).Value.Buff, []byte(".")) {
+						Typ = F32Type
+					}
 				}
 			}
 			Types = append(Types, Typ)
@@ -209,7 +219,11 @@ func (s *SemanticAnalyzer) declaration(dec Declaration) {
 	} else {
 		Types = dec.Types
 	}
+	s.arity(dec, Types)
 
+	for _, typ := range Types {
+		s.typ(typ)
+	}
 	for i, Ident := range dec.Identifiers {
 		if _, ok := s.getSymbol(Ident, true); ok {
 			s.error(string(Ident.Buff)+" has already been declared.", Ident.Line, Ident.Column)
@@ -217,14 +231,18 @@ func (s *SemanticAnalyzer) declaration(dec Declaration) {
 			s.addSymbol(Ident, Types[i])
 		}
 	}
-	for _, typ := range Types {
-		s.typ(typ)
-	}
 	for _, Val := range dec.Values {
 		s.expr(Val)
 	}
 }
 
+// arity reports declarations with more or fewer identifiers than types
+func (s *SemanticAnalyzer) arity(dec Declaration, types []Type) {
+	if len(types) != len(dec.Identifiers) {
+		s.error("Invalid number of types or values specified", dec.Identifiers[0].Line, dec.Identifiers[0].Column)
+	}
+}
+
 func (s *SemanticAnalyzer) exportDeclaration(dec Declaration) {
 	Types := []Type{}
 
@@ -232,12 +250,14 @@ func (s *SemanticAnalyzer) exportDeclaration(dec Declaration) {
 		Type := dec.Types[0]
 
 		if len(dec.Values) == 0 {
-			Types = append(Types, Type)
+			for range dec.Identifiers {
+				Types = append(Types, Type)
+			}
 		}
 		for _, val := range dec.Values {
 			Types = append(Types, Type)
 			Type2 := s.getType(val)
-			if s.compareTypes(Type2, Type) {
+			if s.compareTypes(Type2, Type) || isStringLiteral(val) && isStr(Type, s.getType) {
 				continue
 			}
 			s.error("Type mismatch: val has type {Type2}, expected {Type}.", val.LineM(), val.ColumnM())
@@ -259,6 +279,7 @@ func (s *SemanticAnalyzer) exportDeclaration(dec Declaration) {
 	} else {
 		Types = dec.Types
 	}
+	s.arity(dec, Types)
 	for i, Ident := range dec.Identifiers {
 		if _, ok := s.getSymbol(Ident, true); ok {
 			s.error(string(Ident.Buff)+" has already been declared.", Ident.Line, Ident.Column)
@@ -307,9 +328,11 @@ func (s *SemanticAnalyzer) loop(loop Loop, returnType Type) {
 }
 
 func (s *SemanticAnalyzer) swtch(swtch Switch, returnType Type) {
-	s.popScope()
-	if swtch.Type == 1 {
+	s.pushScope()
+	if swtch.Type == InitCondSwitch {
 		s.basicStmt(swtch.InitStatement)
+	}
+	if swtch.Type != NoneSwtch {
 		s.expr(swtch.Expr)
 	}
 	for _, Case := range swtch.Cases {
@@ -322,6 +345,12 @@ func (s *SemanticAnalyzer) swtch(swtch Switch, returnType Type) {
 	s.popScope()
 }
 
+func (s *SemanticAnalyzer) test(test Test) {
+	s.pushScope()
+	s.block(test.Block, VoidType.Type)
+	s.popScope()
+}
+
 func (s *SemanticAnalyzer) block(block Block, returnType Type) {
 	for _, stmt := range block.Statements {
 		s.stmt(stmt, returnType)
@@ -332,6 +361,10 @@ func (s *SemanticAnalyzer) assignment(as Assignment) {
 	s.exprArray(as.Variables)
 	s.exprArray(as.Values)
 
+	if len(as.Variables) != len(as.Values) {
+		s.error("Assignment mismatch: "+strconv.Itoa(len(as.Variables))+" variables but "+strconv.Itoa(len(as.Values))+" values.", as.Line, as.Column)
+	}
+
 	for x, vr := range as.Variables {
 		val := as.Values[x]
 		s.expr(val)
@@ -352,6 +385,13 @@ func (s *SemanticAnalyzer) assignment(as Assignment) {
 			s.error("Cannot assign to constant variable.", vr.LineM(), vr.ColumnM())
 		}
 
+		switch vr.(type) {
+		case ArrayMemberExpr:
+			if isStr(s.getType(vr.(ArrayMemberExpr).Parent), s.getType) {
+				s.error("Cannot assign to a byte of a str, strs are immutable.", vr.LineM(), vr.ColumnM())
+			}
+		}
+
 		if !s.compareTypes(Type1, Type2) {
 			// s.error("Cannot assign variable of type {Type1} to a value of type {Type2}.", val.LineM(), val.ColumnM())
 		}
@@ -365,10 +405,11 @@ func (s *SemanticAnalyzer) expr(expr Expression) {
 		if isInternal(tok) {
 			break
 		}
-		_, ok := s.getSymbol(tok, false)
+		node, ok := s.getSymbol(tok, false)
 		if !ok {
 			s.error("Use of undeclared variable '"+string(tok.Buff)+"'.", tok.Line, tok.Column)
 		}
+		s.use(tok, s.declPos(node.Identifier), node.Type)
 	case UnaryExpr:
 		s.expr(expr.(UnaryExpr).Expr)
 	case BinaryExpr:
@@ -380,6 +421,10 @@ func (s *SemanticAnalyzer) expr(expr Expression) {
 		lType := s.getType(bExpr.Left)
 		rType := s.getType(bExpr.Right)
 
+		if isStr(lType, s.getType) || isStr(rType, s.getType) {
+			s.strBinary(bExpr, lType, rType)
+			return
+		}
 		if s.compareTypes(lType, rType) {
 			return
 		}
@@ -440,7 +485,7 @@ func (s *SemanticAnalyzer) expr(expr Expression) {
 	case ArrayMemberExpr:
 		s.arrayMemberExpr(expr.(ArrayMemberExpr))
 	case MemberExpr:
-		s.memberExpr(expr.(MemberExpr))
+		s.memberExpr(expr.(MemberExpr), false)
 	case LenExpr:
 		s.lenExpr(expr.(LenExpr))
 	case SizeExpr:
@@ -457,11 +502,41 @@ func (s *SemanticAnalyzer) expr(expr Expression) {
 		s.popScope()
 	case HeapAlloc:
 		s.typ(expr.(HeapAlloc).Type)
+	case InterpolatedString:
+		s.expr(s.interpolate(expr.(InterpolatedString)))
 	}
 }
 
+// interpolate lowers str to calls to string.vo, which must be imported
+func (s *SemanticAnalyzer) interpolate(str InterpolatedString) Expression {
+	if _, ok := s.Imports[StringModule]; !ok {
+		s.error("String interpolation needs import \""+StringModule+".vo\".", str.Line, str.Column)
+	}
+
+	return Interpolate(str, func(expr Expression) string {
+		kind := interpolationKind(expr, s.getType(expr), s.getType)
+		if kind == "" {
+			s.error("Cannot interpolate {expr}, only numbers, bools, pointers and strings can be.", expr.LineM(), expr.ColumnM())
+		}
+		return kind
+	})
+}
+
 func (s *SemanticAnalyzer) callExpr(expr CallExpr) {
-	s.expr(expr.Function)
+	switch expr.Function.(type) {
+	case MemberExpr:
+		// promise is a keyword, promise.all and the other combinators are not members of a value
+		if isPromiseNamespace(expr.Function.(MemberExpr).Base) {
+			s.exprArray(expr.Args)
+			if typ := s.promiseCallType(expr); typ != nil && Uses != nil {
+				s.propUse(expr.Function.(MemberExpr).Prop, Position{}, typ)
+			}
+			return
+		}
+		s.memberExpr(expr.Function.(MemberExpr), true)
+	default:
+		s.expr(expr.Function)
+	}
 	s.exprArray(expr.Args)
 
 	typ := s.getRootType(s.getType(expr.Function))
@@ -488,8 +563,11 @@ func (s *SemanticAnalyzer) callExpr(expr CallExpr) {
 	l := len(expr.Args)
 	l2 := len(typ.(FuncType).ArgTypes)
 
-	first := typ.(FuncType).ArgTypes[0]
-	if s.compareTypes(first, VoidType.Type) {
+	var first Type = VoidType.Type
+	if l2 > 0 {
+		first = typ.(FuncType).ArgTypes[0]
+	}
+	if l2 > 0 && s.compareTypes(first, VoidType.Type) {
 		l2--
 	}
 
@@ -521,19 +599,25 @@ func (s *SemanticAnalyzer) callExpr(expr CallExpr) {
 					l++
 					Args = append([]Expression{base}, expr.Args...)
 				}
-			case VecType:
+			case VecType, PromiseType:
+				// functions of modules can take vectors and promises too, they are not methods
+				switch typ2.(type) {
+				case VecType, PromiseType:
+					l++
+					Args = append([]Expression{UnaryExpr{Expr: base, Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}}}, expr.Args...)
+				}
+			}
+		case VecType, PromiseType:
+			switch typ2.(type) {
+			case VecType, PromiseType:
 				l++
-				Args = append([]Expression{UnaryExpr{Expr: base, Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}}}, expr.Args...)
-			case PromiseType:
+				Args = append([]Expression{base}, expr.Args...)
+			}
+		case BasicType:
+			if isStr(first, s.getType) && isStr(s.getType(base), s.getType) {
 				l++
-				Args = append([]Expression{UnaryExpr{Expr: base, Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}}}, expr.Args...)
+				Args = append([]Expression{base}, expr.Args...)
 			}
-		case VecType:
-			l++
-			Args = append([]Expression{base}, expr.Args...)
-		case PromiseType:
-			l++
-			Args = append([]Expression{base}, expr.Args...)
 		}
 	}
 
@@ -554,6 +638,11 @@ func (s *SemanticAnalyzer) callExpr(expr CallExpr) {
 }
 
 func (s *SemanticAnalyzer) arrayMemberExpr(expr ArrayMemberExpr) {
+	s.expr(expr.Parent)
+	s.expr(expr.Index)
+	if isStr(s.getType(expr.Parent), s.getType) {
+		return
+	}
 	Typ := s.getRootType(s.getType(expr.Parent))
 
 	switch Typ.(type) {
@@ -561,27 +650,44 @@ func (s *SemanticAnalyzer) arrayMemberExpr(expr ArrayMemberExpr) {
 	case ImplictArrayType:
 	case PointerType:
 	case VecType:
-	case TupleType:
 		break
+	case TupleType:
+		// tuples are structs in C, so the index has to be known at compile time
+		index := -1
+		switch expr.Index.(type) {
+		case BasicLit:
+			if i, err := strconv.Atoi(string(expr.Index.(BasicLit).Value.Buff)); err == nil {
+				index = i
+			}
+		}
+		if index < 0 || index >= len(Typ.(TupleType).Types) {
+			s.error("Tuple index must be a number from 0 to "+strconv.Itoa(len(Typ.(TupleType).Types)-1)+".", expr.Index.LineM(), expr.Index.ColumnM())
+		}
 	default:
-		s.error("Type mismatch: expected an array type, got {Typ}.", Typ.LineM(), Typ.ColumnM())
+		s.error("Type mismatch: expected an array type, got {Typ}.", expr.Line, expr.Column)
 	}
 }
 
-func (s *SemanticAnalyzer) memberExpr(expr MemberExpr) {
+// memberExpr checks expr, called is true when expr is the function of a call
+func (s *SemanticAnalyzer) memberExpr(expr MemberExpr, called bool) {
 	Typ1 := s.getType(expr.Base)
 
 	if Typ1 == nil {
 		switch expr.Base.(type) {
 		case IdentExpr:
 			if n, ok := s.Imports[string(expr.Base.(IdentExpr).Value.Buff)]; ok {
-				if _, ok := n.Find(expr.Prop); !ok {
+				node, ok := n.Find(expr.Prop)
+				if !ok {
 					s.error("'"+string(expr.Prop.Buff)+"' is not exported from '"+string(expr.Base.(IdentExpr).Value.Buff)+"'.", expr.Prop.Line, expr.Prop.Column)
 				}
+				if Uses != nil {
+					s.propUse(expr.Prop, Position{Path: n.Path, Line: node.Identifier.Line, Column: node.Identifier.Column}, s.ofNamespace(node.Type, expr.Base, n))
+				}
 				return
 			}
 		}
 	}
+	s.expr(expr.Base)
 
 	isImported := false
 	base := Expression(nil)
@@ -602,17 +708,24 @@ func (s *SemanticAnalyzer) memberExpr(expr MemberExpr) {
 		}
 	}
 
+	if isStr(Typ1, s.getType) {
+		s.propUse(expr.Prop, Position{}, s.getStrPropType(expr.Prop))
+		return
+	}
+
 	Typ := s.getRootType(Typ1)
 
 	switch Typ.(type) {
 	case EnumType:
 		for _, prop := range Typ.(EnumType).Identifiers {
 			if bytes.Compare(prop.Buff, expr.Prop.Buff) == 0 {
+				s.propUse(expr.Prop, Position{Path: s.pathOf(Typ1), Line: prop.Line, Column: prop.Column}, Typ1)
 				return
 			}
 		}
 		s.error("Enum {expr.Base} has no member called '"+string(expr.Prop.Buff)+"'.", expr.Prop.Line, expr.Prop.Column)
 	case StructType:
+		strct := Typ.(StructType)
 		if isImported {
 			Typ8 := Typ.(StructType)
 			Typ9 := StructType{}
@@ -628,8 +741,34 @@ func (s *SemanticAnalyzer) memberExpr(expr MemberExpr) {
 				}
 			}
 			s.getPropType(expr.Prop, Typ9)
+			strct = Typ9
+		}
+		Typ2 := s.getPropType(expr.Prop, Typ.(StructType))
+
+		if Uses != nil {
+			s.propUse(expr.Prop, s.propDecl(expr.Prop, strct, s.pathOf(Typ1)), Typ2)
+		}
+	case UnionType:
+		for x, prop := range Typ.(UnionType).Identifiers {
+			if bytes.Compare(prop.Buff, expr.Prop.Buff) == 0 {
+				s.propUse(expr.Prop, Position{Path: s.pathOf(Typ1), Line: prop.Line, Column: prop.Column}, Typ.(UnionType).Types[x])
+			}
+		}
+	case VecType:
+		if Uses != nil {
+			s.propUse(expr.Prop, Position{}, s.getVectorPropType(Typ.(VecType), expr.Prop))
+		}
+	case PromiseType:
+		// the methods of a promise are macros, there is no function to take
+		switch promisePropType(Typ.(PromiseType), expr.Prop, nil, s.getType).(type) {
+		case FuncType:
+			if !called {
+				s.error("Promise method '"+string(expr.Prop.Buff)+"' can only be called.", expr.Prop.Line, expr.Prop.Column)
+			}
+		}
+		if Uses != nil {
+			s.propUse(expr.Prop, Position{}, s.getPromisePropType(Typ.(PromiseType), expr.Prop, nil))
 		}
-		s.getPropType(expr.Prop, Typ.(StructType))
 	}
 }
 
@@ -673,16 +812,23 @@ func (s *SemanticAnalyzer) compoundLiteral(cl CompoundLiteral) {
 	case ImplictArrayType:
 		break
 	default:
-		s.error("Invalid type in compound literal. Expected struct or tuple type, got {Typ}.", Typ.LineM(), Typ.ColumnM())
+		s.error("Invalid type in compound literal. Expected struct or tuple type, got {Typ}.", cl.Line, cl.Column)
 	}
 }
 
 func (s *SemanticAnalyzer) rturn(stmt Return, returnType Type) {
+	if len(stmt.Values) == 0 {
+		if !s.compareTypes(returnType, VoidType.Type) {
+			s.error("Missing return value.", stmt.Line, stmt.Column)
+		}
+		return
+	}
+
 	s.exprArray(stmt.Values)
 	typ := s.getType(stmt.Values[0])
 
-	if !s.compareTypes(typ, returnType) {
-		s.error("Type mismatch: return statement returns {typ} but function has return type {returnType}", typ.LineM(), typ.ColumnM())
+	if !s.compareTypes(typ, returnType) && !(isStringLiteral(stmt.Values[0]) && isStr(returnType, s.getType)) {
+		s.error("Type mismatch: return statement returns {typ} but function has return type {returnType}", stmt.Values[0].LineM(), stmt.Values[0].ColumnM())
 	}
 }
 
@@ -720,7 +866,15 @@ func (s *SemanticAnalyzer) exprArray(exprs []Expression) {
 func (s *SemanticAnalyzer) typ(typ Type) {
 	switch typ.(type) {
 	case BasicType:
-		s.expr(typ.(BasicType).Expr)
+		expr := typ.(BasicType).Expr
+		s.expr(expr)
+
+		switch s.getType(expr).(type) {
+		case Typedef, InternalType:
+			break
+		default:
+			s.error("{expr} is not a type.", expr.LineM(), expr.ColumnM())
+		}
 	case PointerType:
 		s.typ(typ.(PointerType).BaseType)
 	case CaptureType:
@@ -781,7 +935,7 @@ func (s *SemanticAnalyzer) typ(typ Type) {
 				case Typedef:
 					break
 				default:
-					s.error("Burh gib type.", typ2.LineM(), typ2.ColumnM())
+					s.error("Burh gib type.", typ.LineM(), typ.ColumnM())
 				}
 			}
 		}
@@ -814,11 +968,12 @@ func (s *SemanticAnalyzer) strct(typ StructType) {
 		default:
 			s.error("Expected a struct, got {Typ2}.", superSt.LineM(), superSt.ColumnM())
 		}
-		s.superStrct(Typ2.(StructType), typ)
+		s.superStrct(Typ2.(StructType), typ, []Typedef{Typ1.(Typedef)})
 	}
 }
 
-func (s *SemanticAnalyzer) superStrct(typ StructType, strct StructType) {
+// superStrct declares the props of typ in strct, embedded are the typedefs embedded so far, so that cycles are caught
+func (s *SemanticAnalyzer) superStrct(typ StructType, strct StructType, embedded []Typedef) {
 	for _, prop := range typ.Props {
 		s.superPropDeclaration(prop, typ, strct.Name)
 	}
@@ -838,6 +993,11 @@ func (s *SemanticAnalyzer) superStrct(typ StructType, strct StructType) {
 		default:
 			s.error("Expected a struct typedef, got {Typ1}.", superSt.LineM(), superSt.ColumnM())
 		}
+		for _, t := range embedded {
+			if sameTypedef(t, Typ1.(Typedef)) {
+				s.error("Struct '"+string(t.Name.Buff)+"' embeds itself.", superSt.LineM(), superSt.ColumnM())
+			}
+		}
 		Typ2 := s.getRootType(Typ1)
 
 		switch Typ2.(type) {
@@ -846,10 +1006,14 @@ func (s *SemanticAnalyzer) superStrct(typ StructType, strct StructType) {
 		default:
 			s.error("Expected a struct, got {Typ2}.", superSt.LineM(), superSt.ColumnM())
 		}
-		s.superStrct(Typ2.(StructType), strct)
+		s.superStrct(Typ2.(StructType), strct, append(embedded, Typ1.(Typedef)))
 	}
 }
 
+func sameTypedef(t1 Typedef, t2 Typedef) bool {
+	return bytes.Equal(t1.Name.Buff, t2.Name.Buff) && t1.Name.Line == t2.Name.Line && t1.Name.Column == t2.Name.Column
+}
+
 func (s *SemanticAnalyzer) propDeclaration(dec Declaration) []Type {
 	Types := []Type{}
 
@@ -857,13 +1021,15 @@ func (s *SemanticAnalyzer) propDeclaration(dec Declaration) []Type {
 		Type := dec.Types[0]
 
 		if len(dec.Values) == 0 {
-			Types = append(Types, Type)
+			for range dec.Identifiers {
+				Types = append(Types, Type)
+			}
 		}
 		for _, val := range dec.Values {
 			Types = append(Types, Type)
 			Type2 := s.getType(val)
 
-			if s.compareTypes(Type2, Type) {
+			if s.compareTypes(Type2, Type) || isStringLiteral(val) && isStr(Type, s.getType) {
 				continue
 			}
 			s.error("Type mismatch: val has type {Type2}, expected {Type}.", val.LineM(), val.ColumnM())
@@ -886,6 +1052,7 @@ func (s *SemanticAnalyzer) propDeclaration(dec Declaration) []Type {
 	} else {
 		Types = dec.Types
 	}
+	s.arity(dec, Types)
 
 	for i, Ident := range dec.Identifiers {
 		if _, ok := s.getSymbol(getPropName(Ident), true); ok {
@@ -950,7 +1117,8 @@ func (s *SemanticAnalyzer) superPropDeclaration(dec Declaration, superSt Type, N
 		if _, ok := s.getSymbol(getPropName(Ident), true); ok {
 			s.error(string(Ident.Buff)+" has already been declared.", Ident.Line, Ident.Column)
 		} else {
-			s.addSymbol(getPropName(Ident), Types[i])
+			// not addSymbol, the identifiers belong to the super struct and may be from another file
+			s.Symbols.Add(Node{Identifier: getPropName(Ident), Type: Types[i]})
 		}
 	}
 
@@ -959,6 +1127,8 @@ func (s *SemanticAnalyzer) superPropDeclaration(dec Declaration, superSt Type, N
 
 func (s *SemanticAnalyzer) getType(expr Expression) Type {
 	switch expr.(type) {
+	case InterpolatedString:
+		return s.getType(s.interpolate(expr.(InterpolatedString)))
 	case BasicLit:
 		switch expr.(BasicLit).Value.PrimaryType {
 		case CharLiteral:
@@ -978,6 +1148,7 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 		}
 		sym, ok := s.getSymbol(Ident, false)
 		if ok {
+			s.use(Ident, s.declPos(sym.Identifier), sym.Type)
 			return sym.Type
 		}
 		if _, ok := s.Imports[string(Ident.Buff)]; ok {
@@ -1029,15 +1200,28 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 	case PostfixUnaryExpr:
 		return s.getType(expr.(PostfixUnaryExpr).Expr)
 	case CallExpr:
-		Typ := s.getType(expr.(CallExpr).Function)
+		if Typ := s.promiseCallType(expr.(CallExpr)); Typ != nil {
+			return Typ.(FuncType).ReturnTypes[0]
+		}
+		Typ := s.getRootType(s.getType(expr.(CallExpr).Function))
+
+		switch Typ.(type) {
+		case PointerType:
+			Typ = s.getRootType(Typ.(PointerType).BaseType)
+		}
 
 		switch Typ.(type) {
 		case InternalType:
 			return InternalType{}
+		case FuncType:
+			return Typ.(FuncType).ReturnTypes[0]
 		}
-		return Typ.(FuncType).ReturnTypes[0]
+		s.error("{expr} is not a function or function pointer.", expr.LineM(), expr.ColumnM())
 	case ArrayMemberExpr:
 		Typ := s.getType(expr.(ArrayMemberExpr).Parent)
+		if isStr(Typ, s.getType) {
+			return BasicType{Expr: IdentExpr{Value: U8Token}}
+		}
 
 		switch Typ.(type) {
 		case ArrayType:
@@ -1056,7 +1240,7 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 		return PointerType{BaseType: expr.(HeapAlloc).Type}
 	case CompoundLiteral:
 		return expr.(CompoundLiteral).Name
-	case SizeExpr:
+	case SizeExpr, LenExpr:
 		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("size_t"), PrimaryType: Identifier}}}
 	case ArrayLiteral:
 		return InternalType{}
@@ -1076,7 +1260,7 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 			}
 		}
 
-		Typ := s.getType(expr.(MemberExpr).Base)
+		Typ := unqualified(s.getType(expr.(MemberExpr).Base))
 		isImported := false
 		var base Expression
 		var table *SymbolTable
@@ -1085,7 +1269,14 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 		case PointerType:
 			Typ = Typ.(PointerType).BaseType
 		case Typedef:
-			return Typ.(Typedef).Type // only happens with enums
+			// only happens with enums, the members have the type of the enum so they compare with its variables
+			return BasicType{Expr: expr.(MemberExpr).Base}
+		}
+		if isStr(Typ, s.getType) {
+			return s.getStrPropType(expr.(MemberExpr).Prop)
+		}
+		if isCType(Typ) {
+			return InternalType{}
 		}
 
 		Typ7 := s.getRootType(Typ)
@@ -1132,36 +1323,76 @@ func (s *SemanticAnalyzer) getType(expr Expression) Type {
 		case VecType:
 			return s.getVectorPropType(Typ7.(VecType), expr.(MemberExpr).Prop)
 		case PromiseType:
-			return s.getPromisePropType(Typ7.(PromiseType), expr.(MemberExpr).Prop)
+			return s.getPromisePropType(Typ7.(PromiseType), expr.(MemberExpr).Prop, nil)
 		}
 	}
 
 	return nil
 }
 
-func (s *SemanticAnalyzer) getPromisePropType(prom PromiseType, prop Token) Type {
-	switch string(prop.Buff) {
-	case "then":
-		return FuncType{
-			Type:        OrdFunction,
-			ReturnTypes: []Type{VoidType.Type},
-			ArgTypes:    []Type{prom, FuncType{Type: OrdFunction, ReturnTypes: []Type{VoidType.Type}, ArgTypes: []Type{prom.BaseType}}},
+// getPromisePropType returns the type of a member of prom, args are the arguments it is called with
+func (s *SemanticAnalyzer) getPromisePropType(prom PromiseType, prop Token, args []Expression) Type {
+	typ := promisePropType(prom, prop, args, s.getType)
+	if typ == nil {
+		s.error("Promises have no member called '"+string(prop.Buff)+"'.", prop.Line, prop.Column)
+	}
+	return typ
+}
+
+// promiseCallType returns the type of the member of a promise or of promise.all, promise.race or promise.any called by
+// expr, nil when expr calls something else
+func (s *SemanticAnalyzer) promiseCallType(expr CallExpr) Type {
+	switch expr.Function.(type) {
+	case MemberExpr:
+		break
+	default:
+		return nil
+	}
+	fn := expr.Function.(MemberExpr)
+
+	if isPromiseNamespace(fn.Base) {
+		typ := combinatorType(fn.Prop, expr.Args, s.getType)
+		if typ != nil {
+			return typ
 		}
-	case "resolve":
-		return FuncType{
-			Type:        OrdFunction,
-			ReturnTypes: []Type{VoidType.Type},
-			ArgTypes:    []Type{prom, prom.BaseType},
+		switch name := string(fn.Prop.Buff); name {
+		case "all", "race", "any":
+			s.error("promise."+name+" takes one vector of promises.", fn.Prop.Line, fn.Prop.Column)
+		default:
+			s.error("promise has no function called '"+name+"', only all, race and any.", fn.Prop.Line, fn.Prop.Column)
 		}
-	case "pending":
-		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
-	case "resolved":
-		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
 	}
-	s.error("burh", prop.Line, prop.Column)
+
+	Typ := unqualified(s.getType(fn.Base))
+	switch Typ.(type) {
+	case PointerType:
+		Typ = Typ.(PointerType).BaseType
+	}
+	switch Typ = s.getRootType(Typ); Typ.(type) {
+	case PromiseType:
+		return s.getPromisePropType(Typ.(PromiseType), fn.Prop, expr.Args)
+	}
 	return nil
 }
 
+func (s *SemanticAnalyzer) getStrPropType(prop Token) Type {
+	typ := strPropType(prop)
+	if typ == nil {
+		s.error("str has no member called '"+string(prop.Buff)+"'.", prop.Line, prop.Column)
+	}
+	return typ
+}
+
+// strBinary checks an operator with a str operand, strs can only be compared with strs and string literals
+func (s *SemanticAnalyzer) strBinary(expr BinaryExpr, lType Type, rType Type) {
+	if expr.Op.PrimaryType != RelationalOperator {
+		s.error("Operator "+string(expr.Op.Buff)+" is not defined for str, strs are joined with string.concat.", expr.Op.Line, expr.Op.Column)
+	}
+	if !(isStr(lType, s.getType) || isStringLiteral(expr.Left)) || !(isStr(rType, s.getType) || isStringLiteral(expr.Right)) {
+		s.error("Type mismatch: a str can only be compared with a str.", expr.LineM(), expr.ColumnM())
+	}
+}
+
 func (s *SemanticAnalyzer) getVectorPropType(vec VecType, prop Token) Type {
 	switch string(prop.Buff) {
 	case "push":
@@ -1332,9 +1563,9 @@ func (s *SemanticAnalyzer) compareTypes(Type1 Type, Type2 Type) bool {
 			default:
 				return false
 			}
-			return true
+			return s.compareTypes(BasicType{Expr: Type2.(BasicType).Expr.(MemberExpr).Base}, BasicType{Expr: Type1.(BasicType).Expr.(MemberExpr).Base}) && bytes.Compare(Type2.(BasicType).Expr.(MemberExpr).Prop.Buff, Type1.(BasicType).Expr.(MemberExpr).Prop.Buff) == 0
 		}
-		return s.compareTypes(BasicType{Expr: Type2.(BasicType).Expr.(MemberExpr).Base}, BasicType{Expr: Type1.(BasicType).Expr.(MemberExpr).Base}) && bytes.Compare(Type2.(BasicType).Expr.(MemberExpr).Prop.Buff, Type1.(BasicType).Expr.(MemberExpr).Prop.Buff) == 0
+		return false
 	case PointerType:
 		switch Type2.(type) {
 		case PointerType:
@@ -1445,6 +1676,11 @@ func (s *SemanticAnalyzer) typeCast(typecast TypeCast) {
 		}
 	}
 
+	if isStr(typecast.Type, s.getType) {
+		s.strCast(typecast)
+		return
+	}
+
 	switch type1.(type) {
 	case VecType:
 		switch type2.(type) {
@@ -1464,6 +1700,30 @@ func (s *SemanticAnalyzer) typeCast(typecast TypeCast) {
 	}
 }
 
+// strCast checks a cast to str, which is made from a string literal, a str or the bytes of a C string
+func (s *SemanticAnalyzer) strCast(typecast TypeCast) {
+	typ := s.getType(typecast.Expr)
+	if isStringLiteral(typecast.Expr) || isStr(typ, s.getType) {
+		return
+	}
+
+	switch root := s.getRootType(typ); root.(type) {
+	case PointerType:
+		typ = root.(PointerType).BaseType
+	case ArrayType:
+		typ = root.(ArrayType).BaseType
+	case ImplictArrayType:
+		typ = root.(ImplictArrayType).BaseType
+	case InternalType:
+		return
+	default:
+		s.error("Cannot convert {typ} to str, only pointers to bytes can be.", typecast.LineM(), typecast.ColumnM())
+	}
+	if name, _ := builtinType(typ, s.getType); name != "u8" && name != "i8" && name != "void" {
+		s.error("Cannot convert {typ} to str, only pointers to bytes can be.", typecast.LineM(), typecast.ColumnM())
+	}
+}
+
 func (s *SemanticAnalyzer) getRootType(typ Type) Type {
 	Typ := typ
 
@@ -1472,6 +1732,8 @@ func (s *SemanticAnalyzer) getRootType(typ Type) Type {
 		Typ = s.getType(typ.(BasicType).Expr)
 	case Typedef:
 		break
+	case CaptureType, StaticType:
+		return s.getRootType(unqualified(typ))
 	default:
 		return typ
 	}
@@ -1499,6 +1761,9 @@ func (s *SemanticAnalyzer) lenExpr(lenExpr LenExpr) {
 		Typ = s.getType(lenExpr.Type.(BasicType).Expr)
 	}
 
+	if isStr(Typ, s.getType) {
+		return
+	}
 	Typ = s.getRootType(Typ)
 
 	switch Typ.(type) {
@@ -8,6 +8,8 @@ import (
 type Compiler struct {
 	Buff       []byte
 	ScopeCount int
+	// the post statements of the loops around the statement being compiled, nil for loops without one
+	posts []Statement
 }
 
 func CompileFile(ast File) []byte {
@@ -35,10 +37,8 @@ func CompileOnlyInitializations(ast File) []byte {
 				c.declarationOnlyFunc(stmt2.(Declaration))
 			case Typedef:
 				c.typedefOnlyInit(stmt2.(Typedef))
+				c.newline()
 			}
-		case NullStatement:
-			c.semicolon()
-			c.newline()
 			/*
 				case Import:
 					c.imprt(stmt.(Import))
@@ -70,9 +70,6 @@ func CompileOnlyDeclarations(ast File) []byte {
 				c.typedefOnlyDec(stmt2.(Typedef))
 				c.newline()
 			}
-		case NullStatement:
-			c.semicolon()
-			c.newline()
 		case Import:
 			c.imprt(stmt.(Import))
 		}
@@ -80,6 +77,45 @@ func CompileOnlyDeclarations(ast File) []byte {
 	return c.Buff
 }
 
+// CompileTests compiles the test blocks of ast to functions and a table of them for the runner in test.h, path is reported with failed tests
+func CompileTests(ast File, path string) []byte {
+	c := Compiler{ScopeCount: 0}
+	tests := []Test{}
+
+	for _, stmt := range ast.Statements {
+		switch stmt.(type) {
+		case Test:
+			tests = append(tests, stmt.(Test))
+		}
+	}
+
+	for x, test := range tests {
+		c.newline()
+		c.append([]byte("static void v_test_" + strconv.Itoa(x) + "(void) "))
+		c.block(test.Block)
+		c.newline()
+	}
+
+	c.newline()
+	c.append([]byte("static VTest v_tests[] = {"))
+	c.pushScope()
+	for x, test := range tests {
+		c.newline()
+		c.indent()
+		c.append([]byte("{"))
+		c.append(test.Name.Buff)
+		c.append([]byte(", " + string(cString(path).Value.Buff) + ", " + strconv.Itoa(test.Line) + ", v_test_" + strconv.Itoa(x) + "},"))
+	}
+	c.newline()
+	c.indent()
+	c.append([]byte("{NULL, NULL, 0, NULL},"))
+	c.popScope()
+	c.newline()
+	c.append([]byte("};"))
+	c.newline()
+	return c.Buff
+}
+
 func (c *Compiler) append(buff []byte) {
 	c.Buff = append(c.Buff, []byte(buff)...)
 }
@@ -160,6 +196,9 @@ func (c *Compiler) globalStatement(stmt Statement) {
 
 func (c *Compiler) onlyDeclaration(dec Declaration, isExported bool) {
 	for i, Var := range dec.Identifiers {
+		if i > 0 {
+			c.newline()
+		}
 		if !isExported {
 			c.append([]byte("static"))
 			c.space()
@@ -192,8 +231,10 @@ func (c *Compiler) declarationOnlyFunc(dec Declaration) {
 		c.declarationType(Typ, Var)
 		c.space()
 		c.equal()
+		c.space()
 		c.expression(dec.Values[i])
 		c.semicolon()
+		c.newline()
 	}
 }
 
@@ -241,7 +282,7 @@ func (c *Compiler) statement(stmt Statement) {
 		c.append([]byte("break;"))
 	case Continue:
 		c.indent()
-		c.append([]byte("continue;"))
+		c.cntinue()
 	case NullStatement:
 		c.semicolon()
 	case Block:
@@ -340,11 +381,15 @@ func (c *Compiler) loop(loop Loop) {
 
 	c.closeParen()
 
+	var post Statement
 	if loop.Type&LoopLoop == LoopLoop {
-		loop.Block.Statements = append(loop.Block.Statements, loop.LoopStatement)
+		post = loop.LoopStatement
+		loop.Block.Statements = append(loop.Block.Statements, post)
 	}
 
+	c.posts = append(c.posts, post)
 	c.block(loop.Block)
+	c.posts = c.posts[:len(c.posts)-1]
 
 	if loop.Type&InitLoop == InitLoop {
 		c.popScope()
@@ -354,10 +399,32 @@ func (c *Compiler) loop(loop Loop) {
 	}
 }
 
+// cntinue compiles a continue, the post statement of a loop is at the end of its block, so it is run before continuing
+func (c *Compiler) cntinue() {
+	if len(c.posts) == 0 || c.posts[len(c.posts)-1] == nil {
+		c.append([]byte("continue;"))
+		return
+	}
+
+	c.openCurlyBrace()
+	c.pushScope()
+	c.statement(c.posts[len(c.posts)-1])
+	c.newline()
+	c.indent()
+	c.append([]byte("continue;"))
+	c.popScope()
+	c.newline()
+	c.indent()
+	c.closeCurlyBrace()
+}
+
 func (c *Compiler) globalDeclaration(dec Declaration, isExported bool) {
 	hasValues := len(dec.Values) > 0
 
 	for i, Var := range dec.Identifiers {
+		if i > 0 {
+			c.newline()
+		}
 		if !isExported {
 			c.append([]byte("static"))
 			c.space()
@@ -379,6 +446,9 @@ func (c *Compiler) declaration(dec Declaration) {
 	hasValues := len(dec.Values) > 0
 
 	for i, Var := range dec.Identifiers {
+		if i > 0 {
+			c.newline()
+		}
 		c.indent()
 		c.declarationType(dec.Types[i], Var)
 
@@ -3,8 +3,9 @@ package compiler
 import (
 	"bytes"
 	. "parser"
-	"path"
+	"printer"
 	"strconv"
+	"strings"
 )
 
 type Formatter struct {
@@ -12,10 +13,14 @@ type Formatter struct {
 	Imports  map[string]*SymbolTable
 	Prefixes map[string][]byte
 	NameSp   Namespace
+	Path     string
+
+	local      bool // in a function, where str literals can be statement expressions
+	returnType Type // of the function being formatted, its string literals are strs when it returns a str
 }
 
 func FormatFile(ast File, s *SymbolTable, n map[string]*SymbolTable, p map[string][]byte, num int) File {
-	f := Formatter{s, n, p, Namespace{}}
+	f := Formatter{Symbols: s, Imports: n, Prefixes: p, NameSp: Namespace{}, Path: s.Path}
 	f.NameSp.Init(num)
 	newAst := File{}
 	newAst.Statements = make([]Statement, len(ast.Statements))
@@ -68,12 +73,43 @@ func (f *Formatter) statement(stmt Statement) Statement {
 		return f.delete(stmt.(Delete))
 	case ExportStatement:
 		return ExportStatement{Stmt: f.statement(stmt.(ExportStatement).Stmt)}
+	case Test:
+		return f.test(stmt.(Test))
+	case Assert:
+		return f.assert(stmt.(Assert))
 	case Expression:
 		return f.expr(stmt.(Expression))
 	}
 	return stmt
 }
 
+func (f *Formatter) test(test Test) Test {
+	f.pushScope()
+	test.Block = f.block(test.Block)
+	f.popScope()
+	return test
+}
+
+// assert lowers an assert statement to v_assert, which reports the source of the failed condition and its position
+func (f *Formatter) assert(assert Assert) CallExpr {
+	return CallExpr{
+		Function: IdentExpr{Value: Token{Buff: []byte("v_assert"), PrimaryType: Identifier, Flags: 2}},
+		Args: []Expression{
+			f.expr(assert.Cond),
+			cString(string(printer.PrintExpression(assert.Cond))),
+			cString(f.Path),
+			BasicLit{Value: Token{Buff: []byte(strconv.Itoa(assert.Line)), PrimaryType: NumberLiteral}},
+		},
+	}
+}
+
+var cEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+
+// cString returns a C string literal of str
+func cString(str string) BasicLit {
+	return BasicLit{Value: Token{Buff: []byte("\"" + cEscaper.Replace(str) + "\""), PrimaryType: StringLiteral}}
+}
+
 func (f *Formatter) ifElse(ifElse IfElseBlock) IfElseBlock {
 	f.pushScope()
 	if ifElse.HasInitStmt {
@@ -108,13 +144,16 @@ func (f *Formatter) loop(loop Loop) Loop {
 }
 
 func (f *Formatter) swtch(swtch Switch) Switch {
-	newSwitch := Switch{Cases: make([]CaseStruct, len(swtch.Cases))}
+	newSwitch := Switch{Type: swtch.Type, Line: swtch.Line, Column: swtch.Column, Cases: make([]CaseStruct, len(swtch.Cases))}
 	f.pushScope()
-	if swtch.Type == 1 {
+	if swtch.Type == InitCondSwitch {
 		newSwitch.InitStatement = f.statement(swtch.InitStatement)
+	}
+	if swtch.Type != NoneSwtch {
 		newSwitch.Expr = f.expr(swtch.Expr)
 	}
 	for x, Case := range swtch.Cases {
+		newSwitch.Cases[x].Line, newSwitch.Cases[x].Column = Case.Line, Case.Column
 		newSwitch.Cases[x].Condition = f.expr(Case.Condition)
 		newSwitch.Cases[x].Block = f.block(Case.Block)
 	}
@@ -123,7 +162,7 @@ func (f *Formatter) swtch(swtch Switch) Switch {
 		newSwitch.DefaultCase = f.block(swtch.DefaultCase)
 	}
 	f.popScope()
-	return swtch
+	return newSwitch
 }
 
 func (f *Formatter) imprt(stmt Import) Import {
@@ -131,12 +170,7 @@ func (f *Formatter) imprt(stmt Import) Import {
 	n := strconv.Itoa(f.NameSp.Num + 1)
 
 	for _, Path := range stmt.Paths {
-		pth := path.Clean(string(Path.Buff[1 : len(Path.Buff)-1]))
-		pth = path.Join(path.Dir(pth), n+path.Base(pth))
-
-		if path.Ext(pth) != ".h" {
-			pth += ".h"
-		}
+		pth := headerPath(string(Path.Buff[1:len(Path.Buff)-1]), n)
 		imprt.Paths = append(imprt.Paths, Token{Buff: []byte(pth), PrimaryType: StringLiteral})
 	}
 	return imprt
@@ -183,11 +217,27 @@ func (f *Formatter) delete(delete Delete) Delete {
 }
 
 func (f *Formatter) rturn(rturn Return) Return {
-	return Return{Values: f.exprArray(rturn.Values)}
+	values := []Expression{}
+	for _, val := range rturn.Values {
+		values = append(values, f.typedExpr(val, f.returnType))
+	}
+	return Return{Values: values}
 }
 
 func (f *Formatter) assignment(as Assignment) Assignment {
-	return Assignment{Variables: f.exprArray(as.Variables), Op: as.Op, Values: f.exprArray(as.Values)}
+	values := []Expression{}
+	for x, val := range as.Values {
+		values = append(values, f.typedExpr(val, f.getType(as.Variables[x])))
+	}
+	return Assignment{Variables: f.exprArray(as.Variables), Op: as.Op, Values: values}
+}
+
+// typedExpr formats val where a value of type typ is expected, string literals become str literals where a str is
+func (f *Formatter) typedExpr(val Expression, typ Type) Expression {
+	if isStringLiteral(val) && isStr(typ, f.getType) {
+		return strLiteral(val.(BasicLit), !f.local)
+	}
+	return f.expr(val)
 }
 
 func (f *Formatter) block(block Block) Block {
@@ -217,8 +267,8 @@ func (f *Formatter) declaration(dec Declaration) Declaration {
 		}
 	}
 
-	for _, Val := range dec.Values {
-		newDec.Values = append(newDec.Values, f.expr(Val))
+	for x, Val := range dec.Values {
+		newDec.Values = append(newDec.Values, f.typedExpr(Val, f.declType(dec, x)))
 	}
 	for _, Ident := range dec.Identifiers {
 		newDec.Identifiers = append(newDec.Identifiers, f.NameSp.getNewVarName(Ident))
@@ -226,6 +276,16 @@ func (f *Formatter) declaration(dec Declaration) Declaration {
 	return newDec
 }
 
+// declType returns the type dec declares its xth identifier with, nil if it has none
+func (f *Formatter) declType(dec Declaration, x int) Type {
+	if len(dec.Types) == 1 {
+		return dec.Types[0]
+	} else if x < len(dec.Types) {
+		return dec.Types[x]
+	}
+	return nil
+}
+
 func (f *Formatter) strctProp(prop Declaration, Name Token) Declaration {
 	newProp := Declaration{}
 
@@ -243,8 +303,8 @@ func (f *Formatter) strctProp(prop Declaration, Name Token) Declaration {
 			newProp.Types = append(newProp.Types, f.typ(Type))
 		}
 	}
-	for _, val := range prop.Values {
-		newProp.Values = append(newProp.Values, f.expr(val))
+	for x, val := range prop.Values {
+		newProp.Values = append(newProp.Values, f.typedExpr(val, f.declType(prop, x)))
 	}
 	for i, Ident := range prop.Identifiers {
 		switch newProp.Types[i].(type) {
@@ -368,7 +428,12 @@ func (f *Formatter) expr(expr Expression) Expression {
 	case UnaryExpr:
 		expr2 = UnaryExpr{Op: expr.(UnaryExpr).Op, Expr: f.expr(expr.(UnaryExpr).Expr)}
 	case BinaryExpr:
-		expr2 = BinaryExpr{Left: f.expr(expr.(BinaryExpr).Left), Op: expr.(BinaryExpr).Op, Right: f.expr(expr.(BinaryExpr).Right)}
+		bin := expr.(BinaryExpr)
+		if bin.Op.PrimaryType == RelationalOperator && (isStr(f.getType(bin.Left), f.getType) || isStr(f.getType(bin.Right), f.getType)) {
+			expr2 = strCompare(f.typedExpr(bin.Left, strBasicType), bin.Op, f.typedExpr(bin.Right, strBasicType))
+			break
+		}
+		expr2 = BinaryExpr{Left: f.expr(bin.Left), Op: bin.Op, Right: f.expr(bin.Right)}
 	case PostfixUnaryExpr:
 		expr2 = PostfixUnaryExpr{Op: expr.(PostfixUnaryExpr).Op, Expr: f.expr(expr.(PostfixUnaryExpr).Expr)}
 	case TernaryExpr:
@@ -390,18 +455,45 @@ func (f *Formatter) expr(expr Expression) Expression {
 	case CompoundLiteral:
 		expr2 = f.compoundLiteral(expr.(CompoundLiteral))
 	case FuncExpr:
+		local, returnType := f.local, f.returnType
+		f.local, f.returnType = true, expr.(FuncExpr).Type.ReturnTypes[0]
+
 		f.pushScope()
 		expr2 = FuncExpr{Type: f.typ(expr.(FuncExpr).Type).(FuncType), Block: f.block(expr.(FuncExpr).Block)}
 		f.popScope()
+		f.local, f.returnType = local, returnType
 	case HeapAlloc:
 		expr2 = HeapAlloc{Type: f.typ(expr.(HeapAlloc).Type), Val: f.expr(expr.(HeapAlloc).Val)}
+	case BasicLit:
+		lit := expr.(BasicLit)
+		lit.Value = cLiteral(lit.Value)
+		expr2 = lit
+	case InterpolatedString:
+		expr2 = f.expr(f.interpolate(expr.(InterpolatedString)))
 	}
 	return expr2
 }
 
+func (f *Formatter) interpolate(str InterpolatedString) Expression {
+	return Interpolate(str, func(expr Expression) string {
+		return interpolationKind(expr, f.getType(expr), f.getType)
+	})
+}
+
 func (f *Formatter) callExpr(expr CallExpr) CallExpr {
-	Args := f.exprArray(expr.Args)
-	Function := f.expr(expr.Function)
+	switch expr.Function.(type) {
+	case MemberExpr:
+		if isPromiseNamespace(expr.Function.(MemberExpr).Base) {
+			return CallExpr{Function: promiseProp(nil, expr.Function.(MemberExpr).Prop, expr.Args, f.getType), Args: f.exprArray(expr.Args)}
+		}
+	}
+	var Function Expression
+	if f.promiseCallType(expr) != nil {
+		// which macro then is depends on what the callback returns
+		Function = f.getPromiseProp(expr.Function.(MemberExpr), expr.Args)
+	} else {
+		Function = f.expr(expr.Function)
+	}
 
 	isPointer := false
 	Typ := f.getRootType(f.getType(expr.Function))
@@ -412,8 +504,20 @@ func (f *Formatter) callExpr(expr CallExpr) CallExpr {
 		isPointer = true
 	}
 
+	// the base of a method is formatted before the arguments like the analyzer visits it, functions in both have scopes
+	var self Expression
 	switch expr.Function.(type) {
 	case MemberExpr:
+		switch Typ.(type) {
+		case FuncType:
+			break
+		default:
+			// members of C types are called as they are
+			return CallExpr{Function: Function, Args: f.args(expr.Args, Typ)}
+		}
+		if len(Typ.(FuncType).ArgTypes) == 0 {
+			break
+		}
 		Arg1 := Typ.(FuncType).ArgTypes[0]
 		Base := expr.Function.(MemberExpr).Base
 
@@ -430,27 +534,52 @@ func (f *Formatter) callExpr(expr CallExpr) CallExpr {
 			case PointerType:
 				switch BaseType.(type) {
 				case PointerType:
-					Args = append([]Expression{f.expr(Base)}, Args...)
+					self = f.expr(Base)
 				default:
-					Args = append([]Expression{f.expr(UnaryExpr{Expr: Base, Op: Token{Buff: []byte("&"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}})}, Args...)
+					self = f.expr(UnaryExpr{Expr: Base, Op: Token{Buff: []byte("&"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}})
 				}
 			default:
 				switch BaseType.(type) {
 				case PointerType:
-					Args = append([]Expression{f.expr(UnaryExpr{Expr: Base, Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}})}, Args...)
+					self = f.expr(UnaryExpr{Expr: Base, Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}})
 				default:
-					Args = append([]Expression{f.expr(Base)}, Args...)
+					self = f.expr(Base)
 				}
 			}
 		}
 	}
 
+	Args := f.args(expr.Args, Typ)
+	if self != nil {
+		Args = append([]Expression{self}, Args...)
+	}
+
 	if isPointer {
 		return CallExpr{Function: UnaryExpr{Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}, Expr: Function}, Args: Args}
 	}
 	return CallExpr{Function: Function, Args: Args}
 }
 
+// args formats the arguments of a call to a function of type typ, methods take the base before them
+func (f *Formatter) args(args []Expression, typ Type) []Expression {
+	var argTypes []Type
+	switch typ.(type) {
+	case FuncType:
+		argTypes = typ.(FuncType).ArgTypes
+	}
+
+	Exprs := []Expression{}
+	offset := len(argTypes) - len(args)
+	for x, arg := range args {
+		if offset < 0 {
+			Exprs = append(Exprs, f.expr(arg))
+			continue
+		}
+		Exprs = append(Exprs, f.typedExpr(arg, argTypes[offset+x]))
+	}
+	return Exprs
+}
+
 func (f *Formatter) typ(typ Type) Type {
 	switch typ.(type) {
 	case BasicType:
@@ -507,7 +636,16 @@ func (f *Formatter) typeArray(types []Type) []Type {
 	return typs
 }
 
-func (f *Formatter) typeCast(expr TypeCast) TypeCast {
+func (f *Formatter) typeCast(expr TypeCast) Expression {
+	if isStr(expr.Type, f.getType) {
+		switch {
+		case isStringLiteral(expr.Expr):
+			return strLiteral(expr.Expr.(BasicLit), !f.local)
+		case isStr(f.getType(expr.Expr), f.getType):
+			return f.expr(expr.Expr)
+		}
+		return CallExpr{Function: IdentExpr{Value: Token{Buff: []byte("STR_FROM_CSTRING"), PrimaryType: Identifier}}, Args: []Expression{f.expr(expr.Expr)}}
+	}
 	return TypeCast{Type: f.typ(expr.Type), Expr: f.expr(expr.Expr)}
 	/*
 		switch expr.Type.(type) {
@@ -588,6 +726,22 @@ func (f *Formatter) compoundLiteral(expr CompoundLiteral) CompoundLiteral {
 	strct := Typ.(StructType)
 	data := f.compoundLiteralData(expr.Data)
 
+	for i, val := range expr.Data.Values {
+		if !isStringLiteral(val) {
+			continue
+		}
+		if len(expr.Data.Fields) > 0 {
+			data.Values[i] = f.typedExpr(val, f.getPropType(expr.Data.Fields[i], strct))
+		} else if types := f.fieldTypes(strct); i < len(types) {
+			data.Values[i] = f.typedExpr(val, types[i])
+		}
+	}
+
+	// fields of structs are props, not variables
+	for i, Field := range expr.Data.Fields {
+		data.Fields[i] = f.NameSp.getPropName(Field)
+	}
+
 	if len(data.Fields) == 0 && len(data.Values) > 0 {
 		x := 0
 		l := len(data.Values)
@@ -640,7 +794,7 @@ func (f *Formatter) compoundLiteral(expr CompoundLiteral) CompoundLiteral {
 		for _, prop := range strct.Props {
 			for j, Ident := range prop.Identifiers {
 
-				if hasField(data.Fields, Ident) {
+				if hasField(data.Fields, f.NameSp.getPropName(Ident)) {
 					continue
 				}
 				t := prop.Types[j]
@@ -684,6 +838,24 @@ func (f *Formatter) compoundLiteral(expr CompoundLiteral) CompoundLiteral {
 	return CompoundLiteral{Name: Name, Data: data}
 }
 
+// fieldTypes returns the types of the fields of strct in the order compound literals list them, methods are not fields
+func (f *Formatter) fieldTypes(strct StructType) []Type {
+	types := []Type{}
+	for _, prop := range strct.Props {
+		for j := range prop.Identifiers {
+			t := f.declType(prop, j)
+			switch t.(type) {
+			case FuncType:
+				if !t.(FuncType).Mut {
+					continue
+				}
+			}
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 func hasField(fields []Token, field Token) bool {
 	for _, tok := range fields {
 		if bytes.Compare(tok.Buff, field.Buff) == 0 {
@@ -705,6 +877,12 @@ func (f *Formatter) compoundLiteralData(data CompoundLiteralData) CompoundLitera
 }
 
 func (f *Formatter) arrayMemberExpr(expr ArrayMemberExpr) Expression {
+	if isStr(f.getType(expr.Parent), f.getType) {
+		return ArrayMemberExpr{
+			Parent: MemberExpr{Base: f.expr(expr.Parent), Prop: Token{Buff: []byte("mem"), PrimaryType: Identifier}},
+			Index:  f.expr(expr.Index),
+		}
+	}
 	Typ := f.getRootType(f.getType(expr.Parent))
 
 	switch Typ.(type) {
@@ -734,9 +912,18 @@ func (f *Formatter) lenExpr(expr LenExpr) Expression {
 	case BasicType:
 		Typ = f.getType(Typ.(BasicType).Expr)
 		// Expr = f.expr(Typ.(BasicType).Expr)
+
+		if isStr(Typ, f.getType) {
+			return MemberExpr{Base: f.expr(expr.Type.(BasicType).Expr), Prop: Token{Buff: []byte("length"), PrimaryType: Identifier}}
+		}
 	}
 
 	Typ = f.getRootType(Typ)
+
+	switch Typ.(type) {
+	case ArrayType:
+		return CallExpr{Function: IdentExpr{Value: Token{Buff: []byte("len2")}}, Args: []Expression{f.typ(Typ), f.typ(Typ.(ArrayType).BaseType)}}
+	}
 	/*
 		switch Typ.(type) {
 		case ArrayType:
@@ -767,7 +954,7 @@ func (f *Formatter) sizeExpr(expr SizeExpr) CallExpr {
 }
 
 func (f *Formatter) memberExpr(expr MemberExpr) Expression {
-	Typ := f.getType(expr.Base)
+	Typ := unqualified(f.getType(expr.Base))
 
 	if Typ == nil {
 		switch expr.Base.(type) {
@@ -787,6 +974,21 @@ func (f *Formatter) memberExpr(expr MemberExpr) Expression {
 		isPointer = true
 	}
 
+	if isStr(Typ, f.getType) {
+		if isPointer {
+			return strProp(UnaryExpr{Op: Token{Buff: []byte("*"), PrimaryType: AirthmaticOperator, SecondaryType: Mul}, Expr: f.expr(expr.Base)}, expr.Prop)
+		}
+		return strProp(f.expr(expr.Base), expr.Prop)
+	}
+
+	// the fields of C types keep their C names
+	if isCType(Typ) {
+		if isPointer {
+			return PointerMemberExpr{Base: f.expr(expr.Base), Prop: expr.Prop}
+		}
+		return MemberExpr{Base: f.expr(expr.Base), Prop: expr.Prop}
+	}
+
 	prefix := f.NameSp.Base
 	isImported := false
 	var table *SymbolTable = nil
@@ -835,7 +1037,7 @@ func (f *Formatter) memberExpr(expr MemberExpr) Expression {
 	case VecType:
 		return f.getVecProp(expr)
 	case PromiseType:
-		return f.getPromiseProp(expr)
+		return f.getPromiseProp(expr, nil)
 	default:
 		return MemberExpr{Base: f.expr(expr.Base), Prop: f.NameSp.getPropName(expr.Prop)}
 	}
@@ -899,21 +1101,14 @@ func (f *Formatter) getVecProp(expr MemberExpr) Expression {
 	return nil
 }
 
-func (f *Formatter) getPromiseProp(expr MemberExpr) Expression {
+// getPromiseProp returns what a member of a promise is in C, args are the arguments it is called with
+func (f *Formatter) getPromiseProp(expr MemberExpr, args []Expression) Expression {
 	switch string(expr.Prop.Buff) {
-	case "pending":
-		return UnaryExpr{
-			Op:   Token{Buff: []byte("!"), PrimaryType: LogicalOperator, SecondaryType: Not},
-			Expr: PointerMemberExpr{Base: f.expr(expr.Base), Prop: Token{Buff: []byte("state"), PrimaryType: Identifier}},
-		}
-	case "resolved":
-		return PointerMemberExpr{Base: f.expr(expr.Base), Prop: Token{Buff: []byte("state"), PrimaryType: Identifier}}
-	case "resolve":
-		return IdentExpr{Value: Token{Buff: []byte("PROMISE_RESOLVE")}}
-	case "then":
-		return IdentExpr{Value: Token{Buff: []byte("PROMISE_THEN")}}
+	case "pending", "resolved", "rejected":
+		return promiseProp(f.expr(expr.Base), expr.Prop, args, f.getType)
 	}
-	return nil
+	// methods are passed the base by callExpr, it is formatted once since the functions in it have their own scopes
+	return promiseProp(nil, expr.Prop, args, f.getType)
 }
 
 func (f *Formatter) exprArray(array []Expression) []Expression {
@@ -926,6 +1121,8 @@ func (f *Formatter) exprArray(array []Expression) []Expression {
 
 func (f *Formatter) getType(expr Expression) Type {
 	switch expr.(type) {
+	case InterpolatedString:
+		return f.getType(f.interpolate(expr.(InterpolatedString)))
 	case BasicLit:
 		switch expr.(BasicLit).Value.PrimaryType {
 		case CharLiteral:
@@ -948,6 +1145,10 @@ func (f *Formatter) getType(expr Expression) Type {
 			return sym.Type
 		}
 	case BinaryExpr:
+		if expr.(BinaryExpr).Op.PrimaryType == RelationalOperator {
+			return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
+		}
+
 		lt := f.getType(expr.(BinaryExpr).Left)
 
 		switch lt.(type) {
@@ -986,15 +1187,26 @@ func (f *Formatter) getType(expr Expression) Type {
 	case PostfixUnaryExpr:
 		return f.getType(expr.(PostfixUnaryExpr).Expr)
 	case CallExpr:
-		Typ := f.getType(expr.(CallExpr).Function)
+		if Typ := f.promiseCallType(expr.(CallExpr)); Typ != nil {
+			return Typ.(FuncType).ReturnTypes[0]
+		}
+		Typ := f.getRootType(f.getType(expr.(CallExpr).Function))
 
 		switch Typ.(type) {
-		case InternalType:
-			return InternalType{}
+		case PointerType:
+			Typ = f.getRootType(Typ.(PointerType).BaseType)
+		}
+
+		switch Typ.(type) {
+		case FuncType:
+			return Typ.(FuncType).ReturnTypes[0]
 		}
-		return Typ.(FuncType).ReturnTypes[0]
+		return InternalType{}
 	case ArrayMemberExpr:
 		Typ := f.getType(expr.(ArrayMemberExpr).Parent)
+		if isStr(Typ, f.getType) {
+			return BasicType{Expr: IdentExpr{Value: U8Token}}
+		}
 
 		switch Typ.(type) {
 		case ArrayType:
@@ -1013,10 +1225,10 @@ func (f *Formatter) getType(expr Expression) Type {
 		return PointerType{BaseType: expr.(HeapAlloc).Type}
 	case CompoundLiteral:
 		return expr.(CompoundLiteral).Name
-	case SizeExpr:
+	case SizeExpr, LenExpr:
 		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("size_t"), PrimaryType: Identifier}}}
 	case MemberExpr:
-		Typ := f.getType(expr.(MemberExpr).Base)
+		Typ := unqualified(f.getType(expr.(MemberExpr).Base))
 
 		if Typ == nil {
 			switch expr.(MemberExpr).Base.(type) {
@@ -1039,6 +1251,12 @@ func (f *Formatter) getType(expr Expression) Type {
 		case Typedef:
 			return Typ.(Typedef).Type
 		}
+		if isStr(Typ, f.getType) {
+			return strPropType(expr.(MemberExpr).Prop)
+		}
+		if isCType(Typ) {
+			return InternalType{}
+		}
 
 		Typ7 := f.getRootType(Typ)
 
@@ -1084,31 +1302,40 @@ func (f *Formatter) getType(expr Expression) Type {
 		case VecType:
 			return f.getVectorPropType(Typ.(VecType), expr.(MemberExpr).Prop)
 		case PromiseType:
-			return f.getPromisePropType(Typ.(PromiseType), expr.(MemberExpr).Prop)
+			return f.getPromisePropType(Typ.(PromiseType), expr.(MemberExpr).Prop, nil)
 		}
 	}
 
 	return nil
 }
 
-func (f *Formatter) getPromisePropType(prom PromiseType, prop Token) Type {
-	switch string(prop.Buff) {
-	case "then":
-		return FuncType{
-			Type:        OrdFunction,
-			ReturnTypes: []Type{VoidType.Type},
-			ArgTypes:    []Type{prom, FuncType{Type: OrdFunction, ReturnTypes: []Type{VoidType.Type}, ArgTypes: []Type{prom.BaseType}}},
-		}
-	case "resolve":
-		return FuncType{
-			Type:        OrdFunction,
-			ReturnTypes: []Type{VoidType.Type},
-			ArgTypes:    []Type{prom, prom.BaseType},
-		}
-	case "pending":
-		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
-	case "resolved":
-		return BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
+func (f *Formatter) getPromisePropType(prom PromiseType, prop Token, args []Expression) Type {
+	return promisePropType(prom, prop, args, f.getType)
+}
+
+// promiseCallType returns the type of the member of a promise or of promise.all, promise.race or promise.any called by
+// expr, nil when expr calls something else
+func (f *Formatter) promiseCallType(expr CallExpr) Type {
+	switch expr.Function.(type) {
+	case MemberExpr:
+		break
+	default:
+		return nil
+	}
+	fn := expr.Function.(MemberExpr)
+
+	if isPromiseNamespace(fn.Base) {
+		return combinatorType(fn.Prop, expr.Args, f.getType)
+	}
+
+	Typ := unqualified(f.getType(fn.Base))
+	switch Typ.(type) {
+	case PointerType:
+		Typ = Typ.(PointerType).BaseType
+	}
+	switch Typ = f.getRootType(Typ); Typ.(type) {
+	case PromiseType:
+		return f.getPromisePropType(Typ.(PromiseType), fn.Prop, expr.Args)
 	}
 	return nil
 }
@@ -1182,6 +1409,8 @@ func (f *Formatter) getRootType(typ Type) Type {
 		Typ = f.getType(typ.(BasicType).Expr)
 	case Typedef:
 		break
+	case CaptureType, StaticType:
+		return f.getRootType(unqualified(typ))
 	default:
 		return typ
 	}
@@ -1396,7 +1625,7 @@ func (f *Formatter) ofNamespace(typ Type, name Expression, t *SymbolTable) Type
 	case VecType:
 		return VecType{BaseType: f.ofNamespace(typ.(VecType).BaseType, name, t), Line: typ.LineM(), Column: typ.ColumnM()}
 	case PromiseType:
-		return VecType{BaseType: f.ofNamespace(typ.(PromiseType).BaseType, name, t), Line: typ.LineM(), Column: typ.ColumnM()}
+		return PromiseType{BaseType: f.ofNamespace(typ.(PromiseType).BaseType, name, t), Line: typ.LineM(), Column: typ.ColumnM()}
 	case ArrayType:
 		return ArrayType{BaseType: f.ofNamespace(typ.(ArrayType).BaseType, name, t), Size: typ.(ArrayType).Size, Line: typ.LineM(), Column: typ.ColumnM()}
 	case ImplictArrayType:
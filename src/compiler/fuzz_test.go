@@ -0,0 +1,32 @@
+package compiler_test
+
+import (
+	"compiler"
+	"error"
+	"io/ioutil"
+	"parser"
+	"testing"
+)
+
+func FuzzAnalyzeFile(f *testing.F) {
+	for _, file := range fixtures(f) {
+		if code, err := ioutil.ReadFile(file); err == nil {
+			f.Add(code)
+		}
+	}
+
+	noEmit, importPaths := compiler.NoEmit, compiler.ImportPaths
+	compiler.NoEmit = true
+	compiler.ImportPaths = []string{"../../lib"}
+	defer func() {
+		compiler.NoEmit, compiler.ImportPaths = noEmit, importPaths
+	}()
+
+	f.Fuzz(func(t *testing.T, code []byte) {
+		error.Catch(func() {
+			ast := parser.ParseFile(&parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: "testdata/fuzz.vo"})
+			symbols, imports, prefixes, _, num := compiler.AnalyzeFile(ast, "testdata/fuzz.vo")
+			compiler.FormatFile(ast, symbols, imports, prefixes, num)
+		})
+	})
+}
@@ -0,0 +1,142 @@
+package compiler_test
+
+import (
+	"bytes"
+	"compiler"
+	"dump"
+	"error"
+	"fmt"
+	"internal/golden"
+	"io/ioutil"
+	"os/exec"
+	"parser"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtures are the examples and the sources in testdata/fixtures, files ending in _test.vo are compiled with their tests
+func fixtures(t testing.TB) []string {
+	examples, _ := filepath.Glob("../../examples/*.vo")
+	files, _ := filepath.Glob("testdata/fixtures/*.vo")
+
+	if len(examples) == 0 || len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+	return append(examples, files...)
+}
+
+func TestGolden(t *testing.T) {
+	compiler.ImportPaths = []string{"../../lib"}
+	defer func() {
+		compiler.ImportPaths = nil
+	}()
+
+	for _, file := range fixtures(t) {
+		base := filepath.Join("testdata", "golden", filepath.Base(filepath.Dir(file)), strings.TrimSuffix(filepath.Base(file), ".vo"))
+
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			code, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden.Check(t, base+".tokens", tokens(code, file))
+
+			var ast parser.File
+			if err := error.Catch(func() {
+				ast = parser.ParseFile(&parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: file})
+			}); err == nil {
+				golden.Check(t, base+".ast", dump.Text(dump.Value(ast)))
+			}
+
+			c, diagnostics, _ := generate(t, file)
+			golden.Check(t, base+".diag", diagnostics)
+
+			if c != nil {
+				golden.Check(t, base+".c", c)
+			}
+		})
+	}
+}
+
+// tokens lists the tokens of code, one per line
+func tokens(code []byte, file string) []byte {
+	b := &bytes.Buffer{}
+	lexer := &parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: file}
+
+	error.Catch(func() {
+		for token := lexer.NextToken(); token.PrimaryType != parser.EOF; token = lexer.NextToken() {
+			fmt.Fprintf(b, "%d:%d %s %q\n", token.Line, token.Column, parser.PrimaryTypes[token.PrimaryType], token.Buff)
+			if token.PrimaryType == parser.ErrorToken {
+				break
+			}
+		}
+	})
+	return b.Bytes()
+}
+
+// generate returns the C generated for file and the path it was written to, or the error the parser or analyzer
+// stopped at
+func generate(t *testing.T, file string) ([]byte, []byte, string) {
+	compiler.NoEmit = false
+	compiler.EmitTests = strings.HasSuffix(file, "_test.vo")
+	compiler.BuildDir = filepath.Join(t.TempDir(), "_build")
+	defer func() {
+		compiler.EmitTests = false
+		compiler.BuildDir = ""
+	}()
+
+	if err := error.Catch(func() {
+		compiler.ImportFile(filepath.Dir(file), filepath.Base(file), true, 0)
+	}); err != nil {
+		return nil, []byte(err.Error() + "\n"), ""
+	}
+
+	cFile := filepath.Join(compiler.BuildDir, "0"+filepath.Base(file)+".c")
+	c, err := ioutil.ReadFile(cFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, nil, cFile
+}
+
+// TestCompileC checks that the C generated for the fixtures compiles, it is skipped when clang or the headers of
+// the runtime are not installed
+func TestCompileC(t *testing.T) {
+	cc, err := exec.LookPath("clang")
+	if err != nil {
+		t.Skip("clang is not installed")
+	}
+	lib, _ := filepath.Abs("../../lib")
+	// syntax checks that the C file compiles and returns the output of clang
+	syntax := func(file string) ([]byte, bool) {
+		out, err := exec.Command(cc, "-fsyntax-only", "-fblocks", "-I"+lib, file).CombinedOutput()
+		return out, err == nil
+	}
+
+	// the runtime needs libuv, libgc and the blocks runtime, every generated file includes their headers
+	probe := filepath.Join(t.TempDir(), "probe.c")
+	ioutil.WriteFile(probe, []byte("#include \"internal/default.h\"\n#include <uv.h>\n"), 0644)
+	if out, ok := syntax(probe); !ok {
+		t.Skipf("the headers of libuv, libgc or the blocks runtime are not installed:\n%s", out)
+	}
+
+	compiler.ImportPaths = []string{"../../lib"}
+	defer func() {
+		compiler.ImportPaths = nil
+	}()
+
+	for _, file := range fixtures(t) {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			c, _, cFile := generate(t, file)
+			if c == nil {
+				return
+			}
+			if out, ok := syntax(cFile); !ok {
+				t.Errorf("generated C does not compile:\n%s", out)
+			}
+		})
+	}
+}
@@ -2,12 +2,14 @@ package compiler
 
 import (
 	"error"
+	"io"
 	"io/ioutil"
 	"os"
 	. "parser"
 	"path"
 	Path "path/filepath"
 	"strconv"
+	"strings"
 )
 
 var exPath, _ = os.Executable()
@@ -16,47 +18,155 @@ var libPath = Path.Join(Path.Dir(exPath), "../lib")
 var wd, _ = os.Getwd()
 
 var dfPath = path.Join(libPath, "internal/default.h")
+
+// DefaultC runs the default loop after main until it has no active handles left,
+// so the timers, sockets and requests started by main finish before the program exits
+// promises settled on other threads run their listeners on the thread that runs the loop
 var DefaultC = []byte(`
+#include <uv.h>
+
 int main() {
-	return v0_main();
-}`)
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
+`)
+
+// TestC runs the tests without running the default loop, tests that start handles run the loop themselves
+var TestC = []byte(`
+int main(int argc, char **argv) {
+	_promise_init();
+	return v_test_main(v_tests, argc, argv);
+}
+`)
 
 var ProjectDir string
 
+// BuildDir is where the generated C is written, defaults to _build in the project directory
+var BuildDir string
+
+// EmitTests compiles the test blocks of the main file and a runner for them in place of its main function
+var EmitTests bool
+
+// outDir is the directory the file being compiled is written to, the headers of its imports are written relative to it
+var outDir string
+
+// NoEmit skips writing the generated C, files are only parsed, analyzed and formatted
+var NoEmit bool
+
+// Stdin is read when the main file is "-"
+var Stdin io.Reader = os.Stdin
+
+// ImportPaths are searched in order after the importing file's directory and before VOLANT_PATH and libPath
+var ImportPaths []string
+
+// SearchPath returns the directories an import from dir is looked up in, in order
+func SearchPath(dir string) []string {
+	dirs := []string{dir}
+	dirs = append(dirs, ImportPaths...)
+
+	for _, p := range Path.SplitList(os.Getenv("VOLANT_PATH")) {
+		if p != "" {
+			dirs = append(dirs, p)
+		}
+	}
+	return append(dirs, libPath)
+}
+
+func findImport(dir string, base string) (string, []byte) {
+	tried := []string{}
+
+	for _, d := range SearchPath(dir) {
+		path := Path.Join(d, base)
+		Code, err := ioutil.ReadFile(path)
+
+		if err == nil {
+			return path, Code
+		}
+		tried = append(tried, path)
+	}
+
+	error.NewGenError("error finding import \"" + base + "\", tried:\n\t" + strings.Join(tried, "\n\t"))
+	return "", nil
+}
+
+// headerPath is the path of the header of the import base, relative to the header of the importing file
+// ".." is written as "__" and absolute paths are made relative, so headers never leave the build directory
+func headerPath(base string, n string) string {
+	parts := strings.Split(path.Clean(base), "/")
+	for i, part := range parts {
+		if part == ".." {
+			parts[i] = "__"
+		}
+	}
+	pth := strings.TrimLeft(strings.Join(parts, "/"), "/")
+	pth = path.Join(path.Dir(pth), n+path.Base(pth))
+
+	if path.Ext(pth) != ".h" {
+		pth += ".h"
+	}
+	return pth
+}
+
+// ImportFile compiles base and its imports, an import is only visible in the file that imports it,
+// so a file that uses the types in the results of an import has to import their modules too
 func ImportFile(dir string, base string, isMain bool, num2 int) *SymbolTable {
+	if isMain {
+		// every program is numbered from 0, so that main is always v0_main
+		num = 0
+	}
 	n := strconv.Itoa(num)
 	n2 := strconv.Itoa(num2)
 
 	if isMain {
 		ProjectDir = dir
+		if BuildDir == "" {
+			BuildDir = Path.Join(dir, "_build")
+		}
 	}
 
-	path := Path.Join(dir, base)
-	rel, err := Path.Rel(ProjectDir, path)
+	// the main file is lexed as it is read when it comes from stdin
+	var path string
+	var Code []byte
+	var lexer *Lexer
 
-	if err != nil {
-		rel, _ = Path.Rel(libPath, path)
+	if isMain && base == "-" {
+		path = "<stdin>"
+		lexer = &Lexer{Reader: Stdin, Line: 1, Column: 1, Path: path}
+	} else {
+		path, Code = findImport(dir, base)
+		lexer = &Lexer{Buffer: Code, Line: 1, Column: 1, Path: path}
 	}
 
-	OutPath := Path.Join(ProjectDir, "_build", Path.Dir(rel), n2+Path.Base(base))
+	if NoEmit {
+		if Path.Ext(path) == ".h" {
+			return &SymbolTable{}
+		}
+		ast := ParseFile(lexer)
+		symbols, imports, prefixes, exports, numm := AnalyzeFile(ast, path)
+		FormatFile(ast, symbols, imports, prefixes, numm)
 
-	if isMain {
-		OutPath += ".c"
-	} else if Path.Ext(OutPath) != ".h" {
-		OutPath += ".h"
+		return exports
 	}
 
-	Code, err := ioutil.ReadFile(path)
-
-	if err != nil {
-		path = Path.Join(libPath, base)
-		Code, err = ioutil.ReadFile(path)
+	// a header is written where the #include of the importing file finds it, whichever directory it was found in
+	var OutPath string
+	if isMain {
+		OutPath = Path.Join(BuildDir, n2+Path.Base(base)+".c")
+	} else {
+		OutPath = Path.Join(outDir, Path.FromSlash(headerPath(base, n2)))
 	}
-	if err != nil {
-		error.NewGenError("error finding import: " + err.Error())
+	if rel, err := Path.Rel(BuildDir, OutPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(Path.Separator)) {
+		error.NewGenError("error creating files: " + OutPath + " is not in the build directory " + BuildDir)
 	}
 
 	buildDir := Path.Dir(OutPath)
+	prevOutDir := outDir
+	outDir = buildDir
+	defer func() {
+		outDir = prevOutDir
+	}()
 
 	os.MkdirAll(buildDir, os.ModeDir)
 	os.Chmod(buildDir, 0777)
@@ -70,7 +180,7 @@ func ImportFile(dir string, base string, isMain bool, num2 int) *SymbolTable {
 	if Path.Ext(path) == ".h" {
 		f.Write(Code)
 	} else {
-		ast := ParseFile(&Lexer{Buffer: Code, Line: 1, Column: 1, Path: path})
+		ast := ParseFile(lexer)
 		symbols, imports, prefixes, exports, numm := AnalyzeFile(ast, path)
 		newAst := FormatFile(ast, symbols, imports, prefixes, numm)
 
@@ -82,10 +192,13 @@ func ImportFile(dir string, base string, isMain bool, num2 int) *SymbolTable {
 
 		f.Write(CompileOnlyInitializations(newAst))
 
-		if isMain {
+		if isMain && EmitTests {
+			f.Write(CompileTests(newAst, path))
+			f.Write(TestC)
+		} else if isMain {
 			f.Write(DefaultC)
 		} else {
-			f.Write([]byte("\n#endif"))
+			f.Write([]byte("\n#endif\n"))
 		}
 		f.Close()
 
@@ -0,0 +1,83 @@
+package compiler_test
+
+import (
+	"compiler"
+	"error"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var include = regexp.MustCompile(`#include "([^"]+)"`)
+
+// TestImportHeaders checks that the headers of imports found in ImportPaths, and of the files they import, are written
+// to the build directory where the #include of the importing file finds them, and nowhere else
+func TestImportHeaders(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"project/main.vo":  "import \"util.vo\";\nimport \"../other/far.vo\";\nfunc main() i32 {\n    return util.twice(1) + far.one();\n}\n",
+		"shared/util.vo":   "import \"helper.vo\";\nexport func twice(a: i32) i32 {\n    return helper.add(a, a);\n}\n",
+		"shared/helper.vo": "export func add(a: i32, b: i32) i32 {\n    return a + b;\n}\n",
+		"other/far.vo":     "export func one() i32 {\n    return 1;\n}\n",
+	}
+	for name, code := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	compiler.NoEmit = false
+	compiler.ImportPaths = []string{filepath.Join(dir, "shared")}
+	compiler.BuildDir = filepath.Join(dir, "_build")
+	defer func() {
+		compiler.ImportPaths = nil
+		compiler.BuildDir = ""
+	}()
+
+	if err := error.Catch(func() {
+		compiler.ImportFile(filepath.Join(dir, "project"), "main.vo", true, 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, src := range []string{"project", "shared", "other"} {
+		entries, _ := ioutil.ReadDir(filepath.Join(dir, src))
+		for _, e := range entries {
+			if !strings.HasSuffix(e.Name(), ".vo") {
+				t.Errorf("%s was written to the source directory %s", e.Name(), src)
+			}
+		}
+	}
+
+	// every generated file includes headers that were generated next to it
+	generated := 0
+	var walk func(string)
+	walk = func(d string) {
+		entries, _ := ioutil.ReadDir(d)
+		for _, e := range entries {
+			path := filepath.Join(d, e.Name())
+			if e.IsDir() {
+				walk(path)
+				continue
+			}
+			generated++
+			code, _ := ioutil.ReadFile(path)
+			for _, m := range include.FindAllStringSubmatch(string(code), -1) {
+				if m[1] == "internal/default.h" {
+					continue
+				}
+				if _, err := os.Stat(filepath.Join(d, m[1])); err != nil {
+					t.Errorf("%s includes %s, which was not generated", path, m[1])
+				}
+			}
+		}
+	}
+	walk(compiler.BuildDir)
+	if generated != 4 {
+		t.Errorf("%d files were generated, want 4", generated)
+	}
+}
@@ -0,0 +1,166 @@
+package compiler
+
+import (
+	. "parser"
+	"strings"
+)
+
+// StringModule is the module interpolated strings are built with, it has to be imported where they are used
+const StringModule = "string"
+
+// kinds of values an interpolated string can hold, each has an append function in string.vo
+const (
+	IntKind     = "Int"
+	UintKind    = "Uint"
+	FloatKind   = "Float"
+	BoolKind    = "Bool"
+	PointerKind = "Pointer"
+	CStringKind = "CString"
+	StringKind  = "String"
+	StrKind     = "Str"
+)
+
+// values are cast to the type of the parameter of their append function
+var interpolationCasts = map[string]Type{
+	IntKind:     BasicType{Expr: IdentExpr{Value: I64Token}},
+	UintKind:    BasicType{Expr: IdentExpr{Value: U64Token}},
+	FloatKind:   BasicType{Expr: IdentExpr{Value: F64Token}},
+	PointerKind: PointerType{BaseType: BasicType{Expr: IdentExpr{Value: VoidToken}}},
+	CStringKind: PointerType{BaseType: BasicType{Expr: IdentExpr{Value: U8Token}}},
+}
+
+var builtinKinds = map[string]string{
+	"i8": IntKind, "i16": IntKind, "i32": IntKind, "i64": IntKind,
+	"u8": UintKind, "u16": UintKind, "u32": UintKind, "u64": UintKind, "size_t": UintKind, "uptr": UintKind,
+	"f32": FloatKind, "f64": FloatKind, "bool": BoolKind, "str": StrKind,
+}
+
+// Interpolate lowers str to a chain of calls to the append functions of string.vo, starting with an empty String,
+// kind returns the kind of each expression in str
+func Interpolate(str InterpolatedString, kind func(Expression) string) Expression {
+	line, column := str.Line, str.Column
+	module := IdentExpr{Value: Token{Buff: []byte(StringModule), PrimaryType: Identifier, Line: line, Column: column}, Line: line, Column: column}
+
+	call := func(fn string, args ...Expression) Expression {
+		prop := Token{Buff: []byte(fn), PrimaryType: Identifier, Line: line, Column: column}
+		return CallExpr{Function: MemberExpr{Base: module, Prop: prop, Line: line, Column: column}, Args: args, Line: line, Column: column}
+	}
+
+	expr := call("empty")
+	for x, text := range str.Texts {
+		if len(text.Value.Buff) > 2 {
+			expr = call("append"+CStringKind, expr, text)
+		}
+		if x == len(str.Exprs) {
+			break
+		}
+
+		k := kind(str.Exprs[x])
+		val := str.Exprs[x]
+		if typ, ok := interpolationCasts[k]; ok {
+			val = TypeCast{Type: typ, Expr: val, Line: val.LineM(), Column: val.ColumnM()}
+		}
+		expr = call("append"+k, expr, val)
+	}
+	return expr
+}
+
+// interpolationKind returns the kind of a value of type typ, "" if it cannot be interpolated,
+// getType resolves the names of types
+func interpolationKind(expr Expression, typ Type, getType func(Expression) Type) string {
+	switch typ.(type) {
+	case NumberType:
+		switch expr.(type) {
+		case BasicLit:
+			if strings.Contains(string(expr.(BasicLit).Value.Buff), ".") {
+				return FloatKind
+			}
+		}
+		return IntKind
+	case BasicType:
+		switch e := typ.(BasicType).Expr; e.(type) {
+		case MemberExpr:
+			base, ok := e.(MemberExpr).Base.(IdentExpr)
+			if ok && string(base.Value.Buff) == StringModule && string(e.(MemberExpr).Prop.Buff) == "String" {
+				return StringKind
+			}
+		}
+	}
+
+	name, root := builtinType(typ, getType)
+	if name != "" {
+		return builtinKinds[name]
+	}
+
+	switch root.(type) {
+	case EnumType:
+		return IntKind
+	case PointerType:
+		if name, _ := builtinType(root.(PointerType).BaseType, getType); name == "u8" || name == "i8" {
+			return CStringKind
+		}
+		return PointerKind
+	case ArrayType:
+		if name, _ := builtinType(root.(ArrayType).BaseType, getType); name == "u8" || name == "i8" {
+			return CStringKind
+		}
+	case ImplictArrayType:
+		if name, _ := builtinType(root.(ImplictArrayType).BaseType, getType); name == "u8" || name == "i8" {
+			return CStringKind
+		}
+	}
+	return ""
+}
+
+// builtinType follows the names of typ to the builtin type it is, like u8 for a typedef of u8,
+// or returns the type it is defined with when it is not a builtin type
+func builtinType(typ Type, getType func(Expression) Type) (string, Type) {
+	for {
+		switch typ.(type) {
+		case BasicType:
+			switch typ.(BasicType).Expr.(type) {
+			case IdentExpr:
+				if name := typ.(BasicType).Expr.(IdentExpr).Value.Buff; len(name) > 0 && name[0] == '$' {
+					return string(name[1:]), typ
+				}
+			}
+			typ = getType(typ.(BasicType).Expr)
+		case Typedef:
+			typ = typ.(Typedef).Type
+		default:
+			return "", typ
+		}
+	}
+}
+
+// cLiteral returns a string literal as it is written in C, raw strings are escaped and escaped braces are not
+func cLiteral(token Token) Token {
+	if token.PrimaryType != StringLiteral {
+		return token
+	}
+	str := token.Buff[1 : len(token.Buff)-1]
+
+	if token.SecondaryType == RawString {
+		token.Buff = cString(string(str)).Value.Buff
+		token.SecondaryType = SecondaryNullType
+		return token
+	}
+
+	buff := []byte{'"'}
+	for x := 0; x < len(str); x++ {
+		switch c := str[x]; {
+		case c == '\\' && x+1 < len(str) && (str[x+1] == '{' || str[x+1] == '}'):
+			buff = append(buff, str[x+1])
+			x++
+		case c == '\\' && x+1 < len(str):
+			buff = append(buff, c, str[x+1])
+			x++
+		default:
+			buff = append(buff, c)
+		}
+	}
+
+	token.Buff = append(buff, '"')
+	token.SecondaryType = SecondaryNullType
+	return token
+}
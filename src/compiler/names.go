@@ -107,6 +107,31 @@ func isInternal(tok Token) bool {
 	return len(buff) > 1 && bytes.Compare(buff[:1], []byte("$")) == 0
 }
 
+// isCType reports whether typ is a C type, a $ name or a field of one
+func isCType(typ Type) bool {
+	switch typ.(type) {
+	case InternalType:
+		return true
+	case BasicType:
+		switch typ.(BasicType).Expr.(type) {
+		case IdentExpr:
+			return isInternal(typ.(BasicType).Expr.(IdentExpr).Value)
+		}
+	}
+	return false
+}
+
+// unqualified returns typ without the capture and static qualifiers of a variable, they do not change how it is used
+func unqualified(typ Type) Type {
+	switch typ.(type) {
+	case CaptureType:
+		return unqualified(typ.(CaptureType).BaseType)
+	case StaticType:
+		return unqualified(typ.(StaticType).BaseType)
+	}
+	return typ
+}
+
 func (n *Namespace) getStrctDefaultNameFromPrefix(prefix string, strct Token) Token {
 	if strct.Flags != 0 {
 		return strct
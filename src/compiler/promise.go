@@ -0,0 +1,184 @@
+package compiler
+
+import (
+	. "parser"
+)
+
+var boolBasicType = BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("bool"), PrimaryType: Identifier}}}
+
+// isPromiseNamespace reports whether expr is the promise keyword of promise.all, promise.race and promise.any
+func isPromiseNamespace(expr Expression) bool {
+	switch expr.(type) {
+	case IdentExpr:
+		return expr.(IdentExpr).Value.PrimaryType == PromiseKeyword
+	}
+	return false
+}
+
+// callbackReturn returns the return type of the callback in args, void when there is none
+func callbackReturn(args []Expression, getType func(Expression) Type) Type {
+	if len(args) == 0 {
+		return VoidType.Type
+	}
+	switch _, typ := builtinType(getType(args[0]), getType); typ.(type) {
+	case FuncType:
+		return typ.(FuncType).ReturnTypes[0]
+	case PointerType:
+		switch _, base := builtinType(typ.(PointerType).BaseType, getType); base.(type) {
+		case FuncType:
+			return base.(FuncType).ReturnTypes[0]
+		}
+	}
+	return VoidType.Type
+}
+
+// thenKind tells how then chains a callback that returns ret: "" when it returns nothing and then returns the promise
+// itself, "map" when the value it returns resolves a new promise and "flat" when the promise it returns is followed
+func thenKind(ret Type, getType func(Expression) Type) string {
+	name, typ := builtinType(ret, getType)
+	if name == "void" {
+		return ""
+	}
+	switch typ.(type) {
+	case PromiseType:
+		return "flat"
+	}
+	return "map"
+}
+
+// promisePropType returns the type of the member of prom called prop, nil if there is none
+// args are the arguments of the call of the member, the type of then and the combinators depends on them
+func promisePropType(prom PromiseType, prop Token, args []Expression, getType func(Expression) Type) Type {
+	switch string(prop.Buff) {
+	case "then":
+		ret := callbackReturn(args, getType)
+		var result Type = prom
+		switch thenKind(ret, getType) {
+		case "map":
+			result = PromiseType{BaseType: ret}
+		case "flat":
+			_, result = builtinType(ret, getType)
+		}
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{result},
+			ArgTypes:    []Type{prom, FuncType{Type: OrdFunction, ReturnTypes: []Type{ret}, ArgTypes: []Type{prom.BaseType}}},
+		}
+	case "catch":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{prom},
+			ArgTypes:    []Type{prom, FuncType{Type: OrdFunction, ReturnTypes: []Type{VoidType.Type}, ArgTypes: []Type{strBasicType}}},
+		}
+	case "finally":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{prom},
+			ArgTypes:    []Type{prom, FuncType{Type: OrdFunction, ReturnTypes: []Type{VoidType.Type}, ArgTypes: []Type{VoidType.Type}}},
+		}
+	case "resolve":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{VoidType.Type},
+			ArgTypes:    []Type{prom, prom.BaseType},
+		}
+	case "reject":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{VoidType.Type},
+			ArgTypes:    []Type{prom, strBasicType},
+		}
+	case "pending", "resolved", "rejected":
+		return boolBasicType
+	}
+	return nil
+}
+
+// combinatorType returns the type of promise.all, promise.race or promise.any called with args, nil if prop is none of
+// them or args is not one vector of promises
+func combinatorType(prop Token, args []Expression, getType func(Expression) Type) Type {
+	if len(args) != 1 {
+		return nil
+	}
+	_, vec := builtinType(unqualified(getType(args[0])), getType)
+	switch vec.(type) {
+	case VecType:
+		break
+	default:
+		return nil
+	}
+	_, prom := builtinType(vec.(VecType).BaseType, getType)
+	switch prom.(type) {
+	case PromiseType:
+		break
+	default:
+		return nil
+	}
+
+	var result Type
+	switch string(prop.Buff) {
+	case "all":
+		result = PromiseType{BaseType: VecType{BaseType: prom.(PromiseType).BaseType}}
+	case "race", "any":
+		result = prom
+	default:
+		return nil
+	}
+	return FuncType{
+		Type:        OrdFunction,
+		ReturnTypes: []Type{result},
+		ArgTypes:    []Type{vec},
+	}
+}
+
+// promiseProp returns what a member of a promise is in C, methods are macros of promise.h that are passed the promise first
+func promiseProp(base Expression, prop Token, args []Expression, getType func(Expression) Type) Expression {
+	macro := ""
+	switch string(prop.Buff) {
+	case "pending":
+		return promiseState(base, "PROMISE_PENDING")
+	case "resolved":
+		return promiseState(base, "PROMISE_RESOLVED")
+	case "rejected":
+		return promiseState(base, "PROMISE_REJECTED")
+	case "then":
+		switch thenKind(callbackReturn(args, getType), getType) {
+		case "map":
+			macro = "PROMISE_MAP"
+		case "flat":
+			macro = "PROMISE_FLAT"
+		default:
+			macro = "PROMISE_THEN"
+		}
+	case "resolve":
+		macro = "PROMISE_RESOLVE"
+	case "reject":
+		macro = "PROMISE_REJECT"
+	case "catch":
+		macro = "PROMISE_CATCH"
+	case "finally":
+		macro = "PROMISE_FINALLY"
+	case "all":
+		macro = "PROMISE_ALL"
+	case "race":
+		macro = "PROMISE_RACE"
+	case "any":
+		macro = "PROMISE_ANY"
+	default:
+		return nil
+	}
+	return IdentExpr{Value: Token{Buff: []byte(macro), PrimaryType: Identifier}}
+}
+
+// promiseState compares the state of the promise base with one of the states of promise.h
+// the state is read with PROMISE_STATE since another thread can settle the promise
+func promiseState(base Expression, state string) Expression {
+	return BinaryExpr{
+		Left: CallExpr{
+			Function: IdentExpr{Value: Token{Buff: []byte("PROMISE_STATE"), PrimaryType: Identifier}},
+			Args:     []Expression{base},
+		},
+		Op:    Token{Buff: []byte("=="), PrimaryType: RelationalOperator, SecondaryType: EqualEqual},
+		Right: IdentExpr{Value: Token{Buff: []byte(state), PrimaryType: Identifier}},
+	}
+}
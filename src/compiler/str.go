@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	. "parser"
+	"strconv"
+)
+
+var strBasicType = BasicType{Expr: IdentExpr{Value: StrToken}}
+var sizeTBasicType = BasicType{Expr: IdentExpr{Value: SizeTToken}}
+
+// isStr reports whether typ is str or a name for it, getType resolves the names of types
+func isStr(typ Type, getType func(Expression) Type) bool {
+	for {
+		switch typ.(type) {
+		case ConstType:
+			typ = typ.(ConstType).BaseType
+		case StaticType:
+			typ = typ.(StaticType).BaseType
+		case CaptureType:
+			typ = typ.(CaptureType).BaseType
+		case nil:
+			return false
+		default:
+			name, _ := builtinType(typ, getType)
+			return name == "str"
+		}
+	}
+}
+
+// strPropType returns the type of the member of str called prop, nil if there is none
+func strPropType(prop Token) Type {
+	switch string(prop.Buff) {
+	case "length":
+		return sizeTBasicType
+	case "slice":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{strBasicType},
+			ArgTypes:    []Type{strBasicType, sizeTBasicType, sizeTBasicType},
+		}
+	case "cstr":
+		return FuncType{
+			Type:        OrdFunction,
+			ReturnTypes: []Type{PointerType{BaseType: BasicType{Expr: IdentExpr{Value: U8Token}}}},
+			ArgTypes:    []Type{strBasicType},
+		}
+	}
+	return nil
+}
+
+// strProp returns what a member of str is in C, methods are macros of str.h that are passed the str first
+func strProp(base Expression, prop Token) Expression {
+	switch string(prop.Buff) {
+	case "length":
+		return MemberExpr{Base: base, Prop: prop}
+	case "slice":
+		return IdentExpr{Value: Token{Buff: []byte("STR_SLICE"), PrimaryType: Identifier}}
+	case "cstr":
+		return IdentExpr{Value: Token{Buff: []byte("STR_CSTRING"), PrimaryType: Identifier}}
+	}
+	return nil
+}
+
+// isStringLiteral reports whether expr is a string literal, which becomes a str literal where a str is expected
+func isStringLiteral(expr Expression) bool {
+	switch expr.(type) {
+	case BasicLit:
+		return expr.(BasicLit).Value.PrimaryType == StringLiteral
+	}
+	return false
+}
+
+// strLiteral returns a str literal of lit, its bytes are static and its length is known at compile time,
+// global is true outside of functions where the statement expression of STR_LITERAL is not allowed
+func strLiteral(lit BasicLit, global bool) CallExpr {
+	fn := "STR_LITERAL"
+	if global {
+		fn = "STR_GLOBAL"
+	}
+
+	length := BasicLit{Value: Token{Buff: []byte(strconv.Itoa(lit.Value.Flags - 1)), PrimaryType: NumberLiteral, SecondaryType: DecimalRadix}}
+	return CallExpr{
+		Function: IdentExpr{Value: Token{Buff: []byte(fn), PrimaryType: Identifier}},
+		Args:     []Expression{length, BasicLit{Value: cLiteral(lit.Value)}},
+	}
+}
+
+// strCompare returns the comparison of two strs with op, like strcmp their order is the order of their bytes
+func strCompare(left Expression, op Token, right Expression) BinaryExpr {
+	return BinaryExpr{
+		Left:  CallExpr{Function: IdentExpr{Value: Token{Buff: []byte("STR_COMPARE"), PrimaryType: Identifier}}, Args: []Expression{left, right}},
+		Op:    op,
+		Right: BasicLit{Value: Token{Buff: []byte("0"), PrimaryType: NumberLiteral, SecondaryType: DecimalRadix}},
+	}
+}
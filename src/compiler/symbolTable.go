@@ -10,6 +10,7 @@ type SymbolTable struct {
 	Parent   *SymbolTable
 	Flag     int
 	Children []*SymbolTable
+	Path     string // file the symbols are declared in, only set on the root tables
 }
 
 type Node struct {
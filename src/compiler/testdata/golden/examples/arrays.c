@@ -0,0 +1,50 @@
+#include "internal/default.h"
+#include "1mem.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_array[5];
+	{
+		size_t v0_i = 0;
+		while(v0_i<5){
+			printf("array[%i] = %i.\n", v0_i, v0_array[v0_i]);
+			(++v0_i);
+		}
+	}
+	printf("\n");
+	v1_set((void*)(v0_array), 0, sizeof(v0_array));
+	{
+		size_t v0_i = 0;
+		while(v0_i<5){
+			printf("array[%zu] = %i\n", v0_i, v0_array[v0_i]);
+			(++v0_i);
+		}
+	}
+	printf("\n");
+	i32 v0_array2[5] = {0, 1, 2, 3, 4, };
+	{
+		size_t v0_i = 0;
+		while(v0_i<5){
+			printf("array[%zu] = %i\n", v0_i, v0_array2[v0_i]);
+			(++v0_i);
+		}
+	}
+	printf("\n");
+	i32 v0_array3[5] = (i32[5]){4, 3, 2, 1, 0, };
+	{
+		size_t v0_i = 0;
+		while(v0_i<5){
+			printf("array3[%zu] = %i\n", v0_i, v0_array3[v0_i]);
+			(++v0_i);
+		}
+	}
+	printf("\n");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
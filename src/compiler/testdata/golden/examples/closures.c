@@ -0,0 +1,32 @@
+#include "internal/default.h"
+#include "1callback.vo.h"
+#include "1string.vo.h"
+i32(^(^v0_test)(void))(void) = ^i32(^(void))(void){
+	__block i32 v0_i = 0;
+	return v1_copy(^i32 (void){
+		return v0_i++;
+	});
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 (^v0_function)(void) = v0_test();
+	{
+		i32 v0_i = 0;
+		while(v0_i<100){
+			v2_String v0_line = v2_appendCString(v2_appendInt(v2_empty(), (i64)(v0_function())), " ");
+			printf("%s", m2_bytes_String((&v0_line)));
+			(++v0_i);
+		}
+	}
+	v1_free(v0_function);
+	printf("\n");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
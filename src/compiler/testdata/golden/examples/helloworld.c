@@ -0,0 +1,15 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	v1_println("Hello World!");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
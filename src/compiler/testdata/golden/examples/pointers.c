@@ -0,0 +1,17 @@
+#include "internal/default.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_i = 0;
+	i32 (*v0_ptr) = (&v0_i);
+	printf("ptr is %zu, i is %i.\n", (uptr)(v0_ptr), (*v0_ptr));
+	printf("*(ptr + 1) is %i.\n", (*(v0_ptr+1)));
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
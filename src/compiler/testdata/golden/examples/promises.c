@@ -0,0 +1,21 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	PROMISE_TYPE(i8*)v0_prom;
+	v0_prom = new5(i8*);
+	PROMISE_THEN(v0_prom, ^void (i8 (*v0_val)){
+		v1_println(v0_val);
+	});
+	v1_println("hehe");
+	PROMISE_RESOLVE(v0_prom, "haha");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
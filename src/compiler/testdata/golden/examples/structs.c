@@ -0,0 +1,61 @@
+#include "internal/default.h"
+typedef struct {
+	i8 p_a;
+	i8 p_b;
+} v0_TestStruct;
+v0_TestStruct d0_TestStruct = (v0_TestStruct){.p_b = 100, };
+
+i8 (^m0_c_TestStruct)(v0_TestStruct*);
+
+typedef struct {
+	u32 p_d;
+	i8 p_a;
+	i8 p_b;
+} v0_TestStruct2;
+v0_TestStruct2 d0_TestStruct2 = (v0_TestStruct2){.p_d = 30, .p_b = 100, };
+
+i8 (^m0_c_TestStruct2)(v0_TestStruct2*);
+
+
+i8 (^m0_c_TestStruct)(v0_TestStruct*) = ^i8 (v0_TestStruct (*v0_self)){
+	return (v0_self->p_b)++;
+};
+
+
+i8 (^m0_c_TestStruct2)(v0_TestStruct2*) = ^i8 (v0_TestStruct2 (*v0_self)){
+	return (v0_self->p_b)++;
+};
+
+i32 (^v0_main)(void) = ^i32 (void){
+	v0_TestStruct v0_struct1 = (v0_TestStruct){.p_a = d0_TestStruct.p_a, .p_b = d0_TestStruct.p_b, };
+	printf("struct1.a = %i, struct1.b = %i.\n", v0_struct1.p_a, v0_struct1.p_b);
+	printf("Size of struct1 is %zu.\n", sizeof(v0_struct1));
+	{
+		i32 v0_i = 0;
+		while(v0_i<10){
+			printf("struct1.b = %i.\n", m0_c_TestStruct((&v0_struct1)));
+			(++v0_i);
+		}
+	}
+	printf("\n");
+	v0_TestStruct2 v0_struct2 = (v0_TestStruct2){.p_d = d0_TestStruct2.p_d, .p_a = d0_TestStruct2.p_a, .p_b = d0_TestStruct2.p_b, };
+	printf("struct2.a = %i, struct2.b = %i, struct2.d = %i.\n", v0_struct2.p_a, v0_struct2.p_b, v0_struct2.p_d);
+	printf("Size of struct2 is %zu.\n", sizeof(v0_struct2));
+	{
+		i32 v0_i = 0;
+		while(v0_i<10){
+			printf("struct2.b = %i.\n", m0_c_TestStruct2((&v0_struct2)));
+			(++v0_i);
+		}
+	}
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
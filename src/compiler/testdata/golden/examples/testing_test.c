@@ -0,0 +1,39 @@
+#include "internal/default.h"
+i32 (^v0_fib)(i32) = ^i32 (i32 v0_n){
+	if(v0_n<2){
+		return v0_n;
+	} else {
+	}
+	return (v0_fib(v0_n-1))+(v0_fib(v0_n-2));
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	printf("fib(10) = %i.\n", v0_fib(10));
+	return 0;
+};
+
+static void v_test_0(void) {
+	v_assert((v0_fib(0))==0, "fib(0) == 0", "../../examples/testing_test.vo", 15);
+	v_assert((v0_fib(1))==1, "fib(1) == 1", "../../examples/testing_test.vo", 16);
+	v_assert((v0_fib(2))==1, "fib(2) == 1", "../../examples/testing_test.vo", 17);
+}
+
+static void v_test_1(void) {
+	{
+		i32 v0_i = 2;
+		while(v0_i<10){
+			v_assert((v0_fib(v0_i))>(v0_fib(v0_i-1)), "fib(i) > fib(i - 1)", "../../examples/testing_test.vo", 22);
+			(++v0_i);
+		}
+	}
+}
+
+static VTest v_tests[] = {
+	{"fib of small numbers", "../../examples/testing_test.vo", 14, v_test_0},
+	{"fib grows", "../../examples/testing_test.vo", 20, v_test_1},
+	{NULL, NULL, 0, NULL},
+};
+
+int main(int argc, char **argv) {
+	_promise_init();
+	return v_test_main(v_tests, argc, argv);
+}
@@ -0,0 +1,21 @@
+#include "internal/default.h"
+typedef struct {
+	u8 _0;
+	u32 _1;
+	i16 _2;
+} v0_Tuple;
+
+i32 (^v0_main)(void) = ^i32 (void){
+	v0_Tuple v0_tupl = (v0_Tuple){0, 2, 4, };
+	printf("[0]: %i, [1]: %i, [2]: %i.\n", v0_tupl._0, v0_tupl._1, v0_tupl._2);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
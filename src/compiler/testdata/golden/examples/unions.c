@@ -0,0 +1,28 @@
+#include "internal/default.h"
+typedef union {
+	i32 p_x;
+	u8 p_y[4];
+} v0_Union;
+
+i32 (^v0_main)(void) = ^i32 (void){
+	v0_Union v0_u;
+	v0_u.p_x = 10;
+	printf("u.x is %i\n", v0_u.p_x);
+	{
+		size_t v0_i = 0;
+		while(v0_i<(sizeof(v0_u.p_y))){
+			printf("u.y[%i] is %i.\n", v0_i, v0_u.p_y[v0_i]);
+			(++v0_i);
+		}
+	}
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,35 @@
+#include "internal/default.h"
+#include "1timers.vo.h"
+i32 v0_ticks = 0;
+void (^v0_cb)(void) = ^void (void){
+	static i32 v0_a = 0;
+	printf("tick %i %i\n", v0_a++, v0_ticks);
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	u8 (*v0_x) = new2(u8,u8,(100));
+	v3_setTimeout(1000, ^void (void){
+		printf("x is %u\n", (*v0_x));
+	});
+	__block v3_Timer (*v0_interval);
+	v0_interval = v3_setInterval(100, ^void (void){
+		(++v0_ticks);
+		v0_cb();
+		if(v0_ticks==15){
+			v3_clearInterval(v0_interval);
+		} else {
+		}
+	});
+	PROMISE_THEN(v3_sleep(500), ^void (bool v0_fired){
+		printf("slept\n");
+	});
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,25 @@
+#include "internal/default.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	VECTOR_TYPE(u32)v0_vector1;
+	v0_vector1 = new4(u32, ((u32[]){}), 0);
+	printf("Length: %zu, Capacity: %zu.\n", v0_vector1->length, v0_vector1->capacity);
+	VECTOR_PUSH(v0_vector1, 100);
+	VECTOR_CONCAT(v0_vector1, new4(u32, ((u32[]){50, }), 1));
+	printf("Length: %zu, Capacity: %zu.\n", v0_vector1->length, v0_vector1->capacity);
+	printf("vector1[0] is %u\n", (v0_vector1->mem)[0]);
+	printf("vector1[1] is %u\n", (v0_vector1->mem)[1]);
+	VECTOR_TYPE(u32)v0_vector2 = VECTOR_CLONE(v0_vector1);
+	(v0_vector2->mem)[0] = 99;
+	printf("vector1[0] is %u\n", (v0_vector1->mem)[0]);
+	printf("vector2[0] is %u\n", (v0_vector2->mem)[0]);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
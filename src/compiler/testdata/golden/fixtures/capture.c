@@ -0,0 +1,41 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+typedef struct {
+	i32 p_n;
+} v0_Counter;
+v0_Counter d0_Counter = (v0_Counter){.p_n = 0, };
+
+void (^m0_add_Counter)(v0_Counter*, i32);
+
+
+void (^m0_add_Counter)(v0_Counter*, i32) = ^void (v0_Counter (*v0_self), i32 v0_k){
+	v0_self->p_n = (v0_self->p_n)+v0_k;
+};
+
+i32 (^v0_main)(void) = ^i32 (void){
+	__block i32 v0_count = 0;
+	__block v0_Counter (*v0_c);
+	v0_c = new(v0_Counter,v0_Counter);
+	v0_c->p_n = 0;
+	void (^v0_inc)(void) = ^void (void){
+		(++v0_count);
+		m0_add_Counter(v0_c, v0_count);
+	};
+	v0_inc();
+	v0_inc();
+	static i32 v0_calls = 0;
+	if(((v0_count==2)&&((v0_c->p_n)==3))&&(v0_calls==0)){
+		v1_println("captured");
+	} else {
+	}
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,17 @@
+#include "internal/default.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	div_t v0_d = div(7, 2);
+	div_t (*v0_p) = (&v0_d);
+	v0_p->rem = (v0_p->rem)+1;
+	printf("%i %i\n", v0_d.quot, v0_p->rem);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
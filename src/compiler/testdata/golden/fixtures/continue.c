@@ -0,0 +1,53 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_odd = 0;
+	{
+		i32 v0_i = 0;
+		while(v0_i<10){
+			if((v0_i%2)==0){
+				{
+					(++v0_i);
+					continue;
+				}
+			} else {
+			}
+			{
+				i32 v0_j = 0;
+				while(v0_j<3){
+					(++v0_j);
+					continue;
+				}
+			}
+			(++v0_odd);
+			(++v0_i);
+		}
+	}
+	i32 v0_skipped = 0;
+	{
+		i32 v0_k = 0;
+		while(v0_k<4){
+			switch(v0_k){
+			case 1:
+				(++v0_skipped);
+				{
+					(++v0_k);
+					continue;
+				}
+			}
+			(++v0_k);
+		}
+	}
+	printf("%i %i\n", v0_odd, v0_skipped);
+	v1_println("done");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,39 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+typedef enum {
+	e0_Color_Red,
+	e0_Color_Green,
+	e0_Color_Blue = 10,
+} v0_Color;
+typedef union {
+	i32 p_i;
+	f32 p_f;
+} v0_Value;
+
+
+i32 (^v0_main)(void) = ^i32 (void){
+	enum {
+		Red,
+		Green,
+		Blue = 10,
+} v0_c = e0_Color_Blue;
+	v0_Color v0_d = e0_Color_Red;
+	if((v0_c==e0_Color_Blue)&&(v0_d!=v0_c)){
+		v0_d = e0_Color_Green;
+	} else {
+	}
+	v0_Value v0_v;
+	v0_v.p_i = 10;
+	printf("%i %i %i\n", v0_c, v0_d, v0_v.p_i);
+	v1_println("done");
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,28 @@
+#include "internal/default.h"
+#include "1string.vo.h"
+typedef enum {
+	e0_Color_Red,
+	e0_Color_Green,
+} v0_Color;
+
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_x = 20;
+	f64 v0_f = 1.5;
+	u8 v0_name[7] = "volant";
+	i32 (*v0_p) = (i32*)(null);
+	v1_String v0_inner = v1_appendInt(v1_appendCString(v1_empty(), "inner "), (i64)(v0_x));
+	v1_String v0_s = v1_appendCString(v1_appendCString(v1_appendCString(v1_appendString(v1_appendCString(v1_appendInt(v1_appendCString(v1_appendPointer(v1_appendCString(v1_appendCString(v1_appendCString(v1_appendBool(v1_appendCString(v1_appendFloat(v1_appendCString(v1_appendInt(v1_appendCString(v1_appendInt(v1_appendCString(v1_empty(), "x = "), (i64)(v0_x)), ", x * 2 = "), (i64)(v0_x*2)), ", f = "), (f64)(v0_f)), ", ok = "), v0_x>3), ", name = "), (u8*)(v0_name)), ", p = "), (void*)(v0_p)), ", c = "), (i64)(e0_Color_Green)), ", "), v0_inner), ", {not "), (u8*)("braces")), "}");
+	printf("%s\n", m1_bytes_String((&v0_s)));
+	u8 v0_raw[22] = "a \"raw\"\nstring \\n {x}";
+	printf("%s|\n", v0_raw);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
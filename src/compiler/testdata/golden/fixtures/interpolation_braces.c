@@ -0,0 +1,21 @@
+#include "internal/default.h"
+#include "1string.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_n = 3;
+	str v0_escaped = STR_LITERAL(3, "{n}");
+	str v0_empty = STR_LITERAL(2, "{}");
+	str v0_spaced = STR_LITERAL(6, "{ %d }");
+	v1_String v0_json = v1_appendCString(v1_appendInt(v1_appendCString(v1_empty(), "{\"n\": "), (i64)(v0_n)), "}");
+	printf("{n} {} %d\n", v0_n);
+	printf("%s %s %s %s\n", STR_CSTRING(v0_escaped), STR_CSTRING(v0_empty), STR_CSTRING(v0_spaced), m1_bytes_String((&v0_json)));
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,41 @@
+#include "internal/default.h"
+PROMISE_TYPE(i32) (^v0_parse)(str) = ^PROMISE_TYPE(i32) (str v0_s){
+	PROMISE_TYPE(i32)v0_p = new5(i32);
+	if((v0_s.length)==0){
+		PROMISE_REJECT(v0_p, STR_LITERAL(5, "empty"));
+	} else {
+		PROMISE_RESOLVE(v0_p, (i32)(v0_s.length));
+	}
+	return v0_p;
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	PROMISE_TYPE(str)v0_input = new5(str);
+	PROMISE_FINALLY(PROMISE_CATCH(PROMISE_THEN(PROMISE_MAP(PROMISE_FLAT(v0_input, ^PROMISE_TYPE(i32) (str v0_s){
+		return v0_parse(v0_s);
+	}), ^f64 (i32 v0_n){
+		return ((f64)(v0_n))/2;
+	}), ^void (f64 v0_x){
+		printf("%g\n", v0_x);
+	}), ^void (str v0_reason){
+		printf("failed\n");
+	}), ^void (void){
+		printf("settled\n");
+	});
+	PROMISE_RESOLVE(v0_input, STR_LITERAL(4, "four"));
+	VECTOR_TYPE(PROMISE_TYPE(i32))v0_ps = new4(PROMISE_TYPE(i32), ((PROMISE_TYPE(i32)[]){v0_parse(STR_LITERAL(1, "a")), v0_parse(STR_LITERAL(2, "bc")), }), 2);
+	PROMISE_THEN(PROMISE_ALL(v0_ps), ^void (VECTOR_TYPE(i32)v0_vals){
+		printf("%d %d\n", (v0_vals->mem)[0], (v0_vals->mem)[1]);
+	});
+	PROMISE_TYPE(i32)v0_first = PROMISE_RACE(v0_ps);
+	PROMISE_TYPE(i32)v0_any = PROMISE_ANY(v0_ps);
+	return (i32)((((PROMISE_STATE(v0_first))==PROMISE_RESOLVED)&&((PROMISE_STATE(v0_any))==PROMISE_RESOLVED))&&((!((PROMISE_STATE(v0_input))==PROMISE_REJECTED))));
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,29 @@
+#include "internal/default.h"
+PROMISE_TYPE(i32) (^v0_later)(i32);
+typedef struct {
+	i32 p_n;
+} v0_Counter;
+v0_Counter d0_Counter = (v0_Counter){};
+
+PROMISE_TYPE(i32) (^m0_next_Counter)(v0_Counter*);
+
+PROMISE_TYPE(i32) (^v0_later)(i32) = ^PROMISE_TYPE(i32) (i32 v0_n){
+	PROMISE_TYPE(i32)v0_prom = new5(i32);
+	PROMISE_RESOLVE(v0_prom, v0_n);
+	return v0_prom;
+};
+
+PROMISE_TYPE(i32) (^m0_next_Counter)(v0_Counter*) = ^PROMISE_TYPE(i32) (v0_Counter (*v0_self)){
+	(++(v0_self->p_n));
+	return v0_later(v0_self->p_n);
+};
+
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
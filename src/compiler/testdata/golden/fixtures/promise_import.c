@@ -0,0 +1,22 @@
+#include "internal/default.h"
+#include "1promise_export.vo.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	PROMISE_THEN(v1_later(1), ^void (i32 v0_n){
+		printf("%i\n", v0_n);
+	});
+	v1_Counter (*v0_c) = new(v1_Counter,v1_Counter);
+	v0_c->p_n = 0;
+	PROMISE_THEN(m1_next_Counter(v0_c), ^void (i32 v0_n){
+		printf("%i\n", v0_n);
+	});
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,57 @@
+#include "internal/default.h"
+#include "1string.vo.h"
+typedef struct {
+	str p_name;
+	i32 p_n;
+} v0_Named;
+v0_Named d0_Named = (v0_Named){.p_name = STR_GLOBAL(7, "default"), .p_n = 1, };
+
+
+str v0_greeting = STR_GLOBAL(5, "hello");
+
+
+u8 (^v0_first)(str) = ^u8 (str v0_s){
+	return v0_s.mem[0];
+};
+str (^v0_pick)(bool) = ^str (bool v0_b){
+	if(v0_b){
+		return STR_LITERAL(3, "yes");
+	} else {
+	}
+	return STR_LITERAL(2, "no");
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	str v0_s = STR_LITERAL(12, "hello, world");
+	str v0_hello = STR_SLICE(v0_s, 0, 5);
+	printf("%zu %zu %c\n", v0_s.length, v0_s.length, v0_first(v0_s));
+	u8 (*v0_c) = STR_CSTRING(v0_hello);
+	printf("%s\n", v0_c);
+	if((STR_COMPARE(v0_hello, v0_greeting))==0){
+		printf("equal\n");
+	} else {
+	}
+	if(((STR_COMPARE(v0_hello, STR_LITERAL(4, "help")))<0)&&((STR_COMPARE(v0_s, STR_LITERAL(1, "x")))!=0)){
+		printf("less\n");
+	} else {
+	}
+	v0_Named v0_n = (v0_Named){.p_name = STR_LITERAL(5, "named"), .p_n = d0_Named.p_n, };
+	v0_Named v0_m = (v0_Named){.p_name = STR_LITERAL(10, "positional"), .p_n = 2, };
+	v1_String v0_joined = v1_concat(v0_n.p_name, v0_m.p_name);
+	printf("%s\n", m1_bytes_String((&v0_joined)));
+	str v0_p = STR_FROM_CSTRING(v0_c);
+	str v0_q = STR_LITERAL(3, "lit");
+	v1_String v0_msg = v1_appendStr(v1_appendCString(v1_appendStr(v1_appendCString(v1_appendStr(v1_appendCString(v1_appendStr(v1_appendCString(v1_empty(), "p = "), v0_p), ", q = "), v0_q), ", pick = "), v0_pick(true)), " "), v0_pick(false));
+	printf("%s\n", m1_bytes_String((&v0_msg)));
+	str v0_raw = STR_LITERAL(7, "raw {x}");
+	printf("%zu\n", v0_raw.length);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,21 @@
+#include "internal/default.h"
+i32 (^v0_main)(void) = ^i32 (void){
+	str v0_simple = STR_LITERAL(4, "\n\t\\\"");
+	str v0_octal = STR_LITERAL(2, "\101\0");
+	str v0_hex = STR_LITERAL(1, "\x41");
+	str v0_short = STR_LITERAL(2, "\u00e9");
+	str v0_utf8 = STR_LITERAL(2, "é");
+	str v0_long = STR_LITERAL(4, "\U0001F600");
+	str v0_brace = STR_LITERAL(2, "{}");
+	printf("%zu %zu %zu %zu %zu %zu %zu\n", v0_simple.length, v0_octal.length, v0_hex.length, v0_short.length, v0_utf8.length, v0_long.length, v0_brace.length);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,35 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+str (^v0_name)(i32) = ^str (i32 v0_n){
+	switch(v0_n){
+	case 1:
+		return STR_LITERAL(3, "one");
+	case 2:
+		return STR_LITERAL(3, "two");
+	}
+	return STR_LITERAL(4, "many");
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	i32 v0_total = 0;
+	{
+		str v0_x = v0_name(2);		switch(v0_x.length){
+		case 3:
+			v0_total = 3;
+			break;
+		default:
+			v0_total = (-1);
+		}
+	}
+	v1_println(v0_name(1));
+	printf("%i\n", v0_total);
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,41 @@
+#include "internal/default.h"
+#include "1io.vo.h"
+void (^v0_fill)(VECTOR_TYPE(i32), i32) = ^void (VECTOR_TYPE(i32)v0_v, i32 v0_n){
+	{
+		i32 v0_i = 0;
+		while(v0_i<v0_n){
+			VECTOR_PUSH(v0_v, v0_i);
+			(++v0_i);
+		}
+	}
+};
+i32 (^v0_main)(void) = ^i32 (void){
+	VECTOR_TYPE(i32)v0_v = new4(i32, ((i32[]){}), 0);
+	void (^v0_push)(void) = ^void (void){
+		{
+			i32 v0_i = 0;
+			while(v0_i<10){
+				VECTOR_PUSH(v0_v, v0_i);
+				(++v0_i);
+			}
+		}
+		VECTOR_CONCAT(v0_v, new4(i32, ((i32[]){10, 11, }), 2));
+	};
+	v0_push();
+	v0_fill(v0_v, 20);
+	printf("%zu %d\n", v0_v->length, (v0_v->mem)[11]);
+	if((v0_v->length)==32){
+		v1_println("grown in place");
+	} else {
+	}
+	return 0;
+};
+
+#include <uv.h>
+
+int main() {
+	_promise_init();
+	int code = v0_main();
+	uv_run(uv_default_loop(), UV_RUN_DEFAULT);
+	return code;
+}
@@ -0,0 +1,174 @@
+package compiler
+
+import (
+	"bytes"
+	. "parser"
+)
+
+// Position is the position of a token in a source file
+type Position struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// Use is a declaration, identifier or property resolved by the analyzer
+type Use struct {
+	Name []byte
+	Decl Position // zero for builtins and vector/promise properties
+	Type Type
+	Prop bool // a property of a struct, union, enum, vector, promise or module
+
+	scope SemanticAnalyzer // analyzer state where the use was resolved
+}
+
+// Uses maps the position of everything the analyzer resolves to what it resolved to
+// it is only filled while non nil, tools like the language server set it before analyzing a file
+var Uses map[Position]Use
+
+var vecProps = []string{"push", "pop", "concat", "free", "clone", "length", "capacity"}
+var promiseProps = []string{"then", "catch", "finally", "resolve", "reject", "pending", "resolved", "rejected"}
+
+func (s *SemanticAnalyzer) use(ident Token, decl Position, typ Type) {
+	s.record(ident, Use{Name: ident.Buff, Decl: decl, Type: typ})
+}
+
+func (s *SemanticAnalyzer) propUse(prop Token, decl Position, typ Type) {
+	s.record(prop, Use{Name: prop.Buff, Decl: decl, Type: typ, Prop: true})
+}
+
+func (s *SemanticAnalyzer) record(ident Token, use Use) {
+	if Uses == nil || ident.Line == 0 {
+		return
+	}
+	use.scope = *s
+	Uses[Position{Path: s.Path, Line: ident.Line, Column: ident.Column}] = use
+}
+
+// declPos returns the position of a symbol declared in the current file, zero for builtins
+func (s *SemanticAnalyzer) declPos(ident Token) Position {
+	if ident.Line == 0 {
+		return Position{}
+	}
+	return Position{Path: s.Path, Line: ident.Line, Column: ident.Column}
+}
+
+// pathOf returns the file the named type typ is declared in
+func (s *SemanticAnalyzer) pathOf(typ Type) string {
+	switch typ.(type) {
+	case PointerType:
+		return s.pathOf(typ.(PointerType).BaseType)
+	case BasicType:
+		switch typ.(BasicType).Expr.(type) {
+		case MemberExpr:
+			switch typ.(BasicType).Expr.(MemberExpr).Base.(type) {
+			case IdentExpr:
+				if t, ok := s.Imports[string(typ.(BasicType).Expr.(MemberExpr).Base.(IdentExpr).Value.Buff)]; ok {
+					return t.Path
+				}
+			}
+		}
+	case Typedef:
+		if t, ok := s.Imports[string(typ.(Typedef).NameSpace.Buff)]; ok {
+			return t.Path
+		}
+	}
+	return s.Path
+}
+
+// propDecl returns the position of prop in strct or its super structs, strct is declared in path
+func (s *SemanticAnalyzer) propDecl(prop Token, strct StructType, path string) Position {
+	for _, dec := range strct.Props {
+		for _, ident := range dec.Identifiers {
+			if bytes.Compare(ident.Buff, prop.Buff) == 0 {
+				return Position{Path: path, Line: ident.Line, Column: ident.Column}
+			}
+		}
+	}
+	for _, superSt := range strct.SuperStructs {
+		superPath := path
+		switch superSt.(type) {
+		case MemberExpr:
+			superPath = s.pathOf(BasicType{Expr: superSt})
+		}
+
+		Typ := s.getRootType(s.getType(superSt))
+
+		switch Typ.(type) {
+		case StructType:
+			if pos := s.propDecl(prop, Typ.(StructType), superPath); pos.Line != 0 {
+				return pos
+			}
+		}
+	}
+	return Position{}
+}
+
+// RootType returns the type typ is defined as, looking through typedefs and a pointer, resolved in the scope of u
+func (u Use) RootType(typ Type) Type {
+	switch typ.(type) {
+	case PointerType:
+		typ = typ.(PointerType).BaseType
+	}
+	return u.scope.getRootType(typ)
+}
+
+// Members returns the properties of values of type typ, resolved in the scope of u
+func (u Use) Members(typ Type) []Node {
+	s := u.scope
+	Typ := u.RootType(typ)
+	nodes := []Node{}
+
+	switch Typ.(type) {
+	case StructType:
+		nodes = s.structMembers(Typ.(StructType))
+	case UnionType:
+		for x, ident := range Typ.(UnionType).Identifiers {
+			nodes = append(nodes, Node{Identifier: ident, Type: Typ.(UnionType).Types[x]})
+		}
+	case EnumType:
+		for _, ident := range Typ.(EnumType).Identifiers {
+			nodes = append(nodes, Node{Identifier: ident, Type: typ})
+		}
+	case VecType:
+		for _, prop := range vecProps {
+			ident := Token{Buff: []byte(prop), PrimaryType: Identifier}
+			nodes = append(nodes, Node{Identifier: ident, Type: s.getVectorPropType(Typ.(VecType), ident)})
+		}
+	case PromiseType:
+		for _, prop := range promiseProps {
+			ident := Token{Buff: []byte(prop), PrimaryType: Identifier}
+			nodes = append(nodes, Node{Identifier: ident, Type: s.getPromisePropType(Typ.(PromiseType), ident, nil)})
+		}
+	}
+	return nodes
+}
+
+// Member returns the property called name of values of type typ
+func (u Use) Member(typ Type, name []byte) (Node, bool) {
+	for _, node := range u.Members(typ) {
+		if bytes.Compare(node.Identifier.Buff, name) == 0 {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+func (s *SemanticAnalyzer) structMembers(strct StructType) []Node {
+	nodes := []Node{}
+
+	for _, prop := range strct.Props {
+		for _, ident := range prop.Identifiers {
+			nodes = append(nodes, Node{Identifier: ident, Type: s.getPropType(ident, strct)})
+		}
+	}
+	for _, superSt := range strct.SuperStructs {
+		Typ := s.getRootType(s.getType(superSt))
+
+		switch Typ.(type) {
+		case StructType:
+			nodes = append(nodes, s.structMembers(Typ.(StructType))...)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,275 @@
+// Package doc extracts the exported API of a module and its doc comments and renders it as Markdown or HTML
+package doc
+
+import (
+	"bytes"
+	"error"
+	"io/ioutil"
+	. "parser"
+	"path"
+	"printer"
+	"sort"
+	"strings"
+)
+
+// Module is the documentation of a single source file
+type Module struct {
+	Name    string // identifier importers refer to the module by
+	Import  string // path the module is imported with
+	Doc     string
+	Funcs   []Func
+	Structs []Struct
+	Enums   []Enum
+	Unions  []Union
+	Aliases []Alias
+	Vars    []Var
+}
+
+type Func struct {
+	Name      string
+	Signature string
+	Doc       string
+}
+
+type Field struct {
+	Name string
+	Type string
+	Doc  string
+}
+
+type Struct struct {
+	Name       string
+	Definition string
+	Doc        string
+	Fields     []Field
+	Methods    []Func
+}
+
+type Enum struct {
+	Name       string
+	Definition string
+	Doc        string
+	Members    []Field
+}
+
+type Union struct {
+	Name       string
+	Definition string
+	Doc        string
+	Fields     []Field
+}
+
+// Alias is any other typedef, like tuples and renamed types
+type Alias struct {
+	Name       string
+	Definition string
+	Doc        string
+}
+
+type Var struct {
+	Name string
+	Type string // ":= value" when the type is inferred
+	Doc  string
+}
+
+// Declaration returns the source declaring v, without its value if it has a type
+func (v Var) Declaration() string {
+	if strings.HasPrefix(v.Type, ":=") {
+		return v.Name + " " + v.Type
+	}
+	return v.Name + ": " + v.Type
+}
+
+// Load parses the module in file, importPath is the path it is imported with
+func Load(file string, importPath string) (Module, *error.Error) {
+	code, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Module{}, &error.Error{Message: "error reading file: " + err.Error()}
+	}
+
+	var ast File
+	if err := error.Catch(func() {
+		ast = ParseFile(&Lexer{Buffer: code, Line: 1, Column: 1, Path: file, KeepComments: true})
+	}); err != nil {
+		return Module{}, err
+	}
+
+	base := path.Base(importPath)
+	return NewModule(ast, strings.TrimSuffix(base, path.Ext(base)), importPath), nil
+}
+
+// NewModule collects the exported declarations of ast, which should be parsed with the lexer's KeepComments set
+func NewModule(ast File, name string, importPath string) Module {
+	m := Module{Name: name, Import: importPath, Doc: moduleDoc(ast)}
+
+	for _, stmt := range ast.Statements {
+		switch stmt.(type) {
+		case ExportStatement:
+			break
+		default:
+			continue
+		}
+
+		st := stmt.(ExportStatement).Stmt
+
+		switch st.(type) {
+		case Declaration:
+			m.declaration(st.(Declaration))
+		case Typedef:
+			m.typedef(st.(Typedef))
+		}
+	}
+	return m
+}
+
+// moduleDoc returns the comments at the top of the file, when a blank line separates them from the first statement
+func moduleDoc(ast File) string {
+	first := 0
+	for _, stmt := range ast.Statements {
+		if first = stmt.LineM(); first != 0 {
+			break
+		}
+	}
+
+	block := []Comment{}
+	for _, comment := range ast.Comments {
+		if comment.Trailing || first != 0 && comment.Line >= first || len(block) > 0 && comment.BlankLines > 0 {
+			break
+		}
+		block = append(block, comment)
+	}
+
+	if len(block) == 0 {
+		return ""
+	}
+	if first == 0 {
+		return Text(block)
+	}
+
+	last := block[len(block)-1]
+	end := last.Line + bytes.Count(last.Text, []byte("\n"))
+
+	for _, line := range ast.BlankLines {
+		if line == end+1 {
+			return Text(block)
+		}
+	}
+	return ""
+}
+
+// Text returns the text of comments without their delimiters
+func Text(comments []Comment) string {
+	lines := []string{}
+
+	for _, comment := range comments {
+		text := string(comment.Text)
+
+		if strings.HasPrefix(text, "//") {
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(text, "//"), " "))
+			continue
+		}
+
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isMethod(dec Declaration, x int) bool {
+	if x >= len(dec.Values) {
+		return false
+	}
+	switch dec.Values[x].(type) {
+	case FuncExpr:
+		return !dec.Values[x].(FuncExpr).Type.Mut
+	}
+	return false
+}
+
+func declaredType(dec Declaration, x int) string {
+	if len(dec.Types) == 1 {
+		return string(printer.PrintType(dec.Types[0]))
+	} else if x < len(dec.Types) {
+		return string(printer.PrintType(dec.Types[x]))
+	} else if x < len(dec.Values) {
+		switch dec.Values[x].(type) {
+		case FuncExpr:
+			return string(printer.PrintType(dec.Values[x].(FuncExpr).Type))
+		}
+		return ":= " + string(printer.PrintExpression(dec.Values[x]))
+	}
+	return ""
+}
+
+func (m *Module) declaration(dec Declaration) {
+	doc := Text(dec.Doc)
+
+	for x, ident := range dec.Identifiers {
+		if isMethod(dec, x) {
+			m.Funcs = append(m.Funcs, Func{Name: string(ident.Buff), Signature: string(printer.PrintFuncHead(ident, dec.Values[x].(FuncExpr).Type)), Doc: doc})
+		} else {
+			m.Vars = append(m.Vars, Var{Name: string(ident.Buff), Type: declaredType(dec, x), Doc: doc})
+		}
+	}
+}
+
+func (m *Module) typedef(typedef Typedef) {
+	name := string(typedef.Name.Buff)
+	doc := Text(typedef.Doc)
+
+	switch typedef.Type.(type) {
+	case StructType:
+		m.Structs = append(m.Structs, newStruct(typedef, doc))
+	case EnumType:
+		enum := Enum{Name: name, Definition: string(printer.PrintTypedef(typedef)), Doc: doc}
+		for _, ident := range typedef.Type.(EnumType).Identifiers {
+			enum.Members = append(enum.Members, Field{Name: string(ident.Buff), Doc: Text(DocComments(ident))})
+		}
+		m.Enums = append(m.Enums, enum)
+	case UnionType:
+		union := Union{Name: name, Definition: string(printer.PrintTypedef(typedef)), Doc: doc}
+		for x, ident := range typedef.Type.(UnionType).Identifiers {
+			union.Fields = append(union.Fields, Field{Name: string(ident.Buff), Type: string(printer.PrintType(typedef.Type.(UnionType).Types[x])), Doc: Text(DocComments(ident))})
+		}
+		m.Unions = append(m.Unions, union)
+	default:
+		m.Aliases = append(m.Aliases, Alias{Name: name, Definition: string(printer.PrintTypedef(typedef)), Doc: doc})
+	}
+}
+
+// newStruct documents a struct, its definition leaves out the methods which are listed separately
+func newStruct(typedef Typedef, doc string) Struct {
+	strct := typedef.Type.(StructType)
+	s := Struct{Name: string(typedef.Name.Buff), Doc: doc}
+	fields := StructType{Name: strct.Name, SuperStructs: strct.SuperStructs}
+
+	for _, prop := range strct.Props {
+		propDoc := Text(prop.Doc)
+		for x, ident := range prop.Identifiers {
+			if isMethod(prop, x) {
+				s.Methods = append(s.Methods, Func{Name: string(ident.Buff), Signature: string(printer.PrintFuncHead(ident, prop.Values[x].(FuncExpr).Type)), Doc: propDoc})
+				continue
+			}
+			s.Fields = append(s.Fields, Field{Name: string(ident.Buff), Type: declaredType(prop, x), Doc: propDoc})
+		}
+		if !isMethod(prop, 0) {
+			fields.Props = append(fields.Props, prop)
+		}
+	}
+
+	typedef.Type = fields
+	s.Definition = string(printer.PrintTypedef(typedef))
+	return s
+}
+
+// Sort orders modules by import path
+func Sort(modules []Module) {
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Import < modules[j].Import
+	})
+}
@@ -0,0 +1,21 @@
+package doc_test
+
+import (
+	"doc"
+	"internal/golden"
+	"testing"
+)
+
+// TestRender renders the fixture module as Markdown and HTML, with its index, and compares them with the golden files
+func TestRender(t *testing.T) {
+	m, err := doc.Load("testdata/shapes.vo", "geometry/shapes.vo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules := []doc.Module{m}
+
+	golden.Check(t, "testdata/shapes.md", doc.Markdown(modules))
+	golden.Check(t, "testdata/index.md", doc.MarkdownIndex(modules))
+	golden.Check(t, "testdata/shapes.html", doc.HTML(m.Name, "../", modules))
+	golden.Check(t, "testdata/index.html", doc.HTMLIndex(modules))
+}
@@ -0,0 +1,155 @@
+package doc
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+)
+
+var funcs = template.FuncMap{
+	"paragraphs": func(text string) []string {
+		paragraphs := []string{}
+		for _, p := range strings.Split(text, "\n\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				paragraphs = append(paragraphs, p)
+			}
+		}
+		return paragraphs
+	},
+	"documented": func(fields []Field) bool {
+		for _, field := range fields {
+			if field.Doc != "" {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+var page = template.Must(template.New("page").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+pre { background: #f4f4f4; padding: 0.75em; overflow-x: auto; }
+code, pre { font-family: monospace; }
+h2 { border-bottom: 1px solid #ddd; }
+dt { font-family: monospace; }
+</style>
+</head>
+<body>
+{{- if .Root}}
+<p><a href="{{.Root}}index.html">Modules</a></p>
+{{- end}}
+{{- range .Modules}}
+<h1 id="{{.Name}}">{{.Name}}</h1>
+<pre>import "{{.Import}}";</pre>
+{{- template "doc" .Doc}}
+{{- if .Funcs}}
+<h2>Functions</h2>
+{{- range .Funcs}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Signature}}</pre>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+{{- if .Structs}}
+<h2>Structs</h2>
+{{- range $s := .Structs}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Definition}}</pre>
+{{- template "doc" .Doc}}
+{{- template "fields" .Fields}}
+{{- range .Methods}}
+<h4 id="{{$s.Name}}.{{.Name}}">{{$s.Name}}.{{.Name}}</h4>
+<pre>{{.Signature}}</pre>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+<h2>Enums</h2>
+{{- range .Enums}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Definition}}</pre>
+{{- template "doc" .Doc}}
+{{- template "fields" .Members}}
+{{- end}}
+{{- end}}
+{{- if .Unions}}
+<h2>Unions</h2>
+{{- range .Unions}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Definition}}</pre>
+{{- template "doc" .Doc}}
+{{- template "fields" .Fields}}
+{{- end}}
+{{- end}}
+{{- if .Aliases}}
+<h2>Types</h2>
+{{- range .Aliases}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Definition}}</pre>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+{{- if .Vars}}
+<h2>Variables</h2>
+{{- range .Vars}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+<pre>{{.Declaration}}</pre>
+{{- template "doc" .Doc}}
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{define "doc"}}{{range paragraphs .}}
+<p>{{.}}</p>{{end}}{{end}}
+{{- define "fields"}}{{if documented .}}
+<dl>{{range .}}{{if .Doc}}
+<dt>{{.Name}}{{if .Type}} {{.Type}}{{end}}</dt>
+<dd>{{.Doc}}</dd>{{end}}{{end}}
+</dl>{{end}}{{end}}
+`))
+
+var index = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Modules</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+</style>
+</head>
+<body>
+<h1>Modules</h1>
+<dl>
+{{- range .}}
+<dt><a href="{{.File ".html"}}">{{.Import}}</a></dt>
+<dd>{{.Synopsis}}</dd>
+{{- end}}
+</dl>
+</body>
+</html>
+`))
+
+// HTML renders modules as a single HTML page, root is the path from the page to the directory of the index or empty if there is none
+func HTML(title string, root string, modules []Module) []byte {
+	b := &bytes.Buffer{}
+	page.Execute(b, struct {
+		Title   string
+		Root    string
+		Modules []Module
+	}{title, root, modules})
+	return b.Bytes()
+}
+
+// HTMLIndex renders a page linking to the HTML files of modules
+func HTMLIndex(modules []Module) []byte {
+	b := &bytes.Buffer{}
+	index.Execute(b, modules)
+	return b.Bytes()
+}
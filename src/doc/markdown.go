@@ -0,0 +1,146 @@
+package doc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// File returns the path of the rendered module relative to the index, with extension ext
+func (m Module) File(ext string) string {
+	return strings.TrimSuffix(m.Import, ".vo") + ext
+}
+
+// Synopsis returns the first sentence of the module's doc comment
+func (m Module) Synopsis() string {
+	doc := strings.Join(strings.Fields(m.Doc), " ")
+	if i := strings.Index(doc, ". "); i != -1 {
+		return doc[:i+1]
+	}
+	return doc
+}
+
+// Markdown renders modules as a single Markdown document
+func Markdown(modules []Module) []byte {
+	b := &bytes.Buffer{}
+	for _, m := range modules {
+		m.markdown(b)
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// MarkdownIndex renders a list of modules linking to their Markdown files
+func MarkdownIndex(modules []Module) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("# Modules\n\n")
+
+	for _, m := range modules {
+		fmt.Fprintf(b, "- [%s](%s)", m.Import, m.File(".md"))
+		if synopsis := m.Synopsis(); synopsis != "" {
+			fmt.Fprintf(b, ": %s", synopsis)
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+func code(b *bytes.Buffer, source string) {
+	fmt.Fprintf(b, "```volant\n%s\n```\n\n", source)
+}
+
+func paragraph(b *bytes.Buffer, text string) {
+	if text != "" {
+		fmt.Fprintf(b, "%s\n\n", text)
+	}
+}
+
+// fields lists the fields which have a doc comment, the rest are clear from the definition
+func fields(b *bytes.Buffer, fields []Field) {
+	documented := false
+
+	for _, field := range fields {
+		if field.Doc == "" {
+			continue
+		}
+		documented = true
+
+		fmt.Fprintf(b, "- `%s`", field.Name)
+		if field.Type != "" {
+			fmt.Fprintf(b, " `%s`", field.Type)
+		}
+		fmt.Fprintf(b, ": %s\n", strings.Replace(field.Doc, "\n", " ", -1))
+	}
+
+	if documented {
+		b.WriteString("\n")
+	}
+}
+
+func (m Module) markdown(b *bytes.Buffer) {
+	fmt.Fprintf(b, "# %s\n\n", m.Name)
+	code(b, "import \""+m.Import+"\";")
+	paragraph(b, m.Doc)
+
+	if len(m.Funcs) > 0 {
+		b.WriteString("## Functions\n\n")
+		for _, f := range m.Funcs {
+			fmt.Fprintf(b, "### %s\n\n", f.Name)
+			code(b, f.Signature)
+			paragraph(b, f.Doc)
+		}
+	}
+
+	if len(m.Structs) > 0 {
+		b.WriteString("## Structs\n\n")
+		for _, s := range m.Structs {
+			fmt.Fprintf(b, "### %s\n\n", s.Name)
+			code(b, s.Definition)
+			paragraph(b, s.Doc)
+			fields(b, s.Fields)
+
+			for _, method := range s.Methods {
+				fmt.Fprintf(b, "#### %s.%s\n\n", s.Name, method.Name)
+				code(b, method.Signature)
+				paragraph(b, method.Doc)
+			}
+		}
+	}
+
+	if len(m.Enums) > 0 {
+		b.WriteString("## Enums\n\n")
+		for _, e := range m.Enums {
+			fmt.Fprintf(b, "### %s\n\n", e.Name)
+			code(b, e.Definition)
+			paragraph(b, e.Doc)
+			fields(b, e.Members)
+		}
+	}
+
+	if len(m.Unions) > 0 {
+		b.WriteString("## Unions\n\n")
+		for _, u := range m.Unions {
+			fmt.Fprintf(b, "### %s\n\n", u.Name)
+			code(b, u.Definition)
+			paragraph(b, u.Doc)
+			fields(b, u.Fields)
+		}
+	}
+
+	if len(m.Aliases) > 0 {
+		b.WriteString("## Types\n\n")
+		for _, a := range m.Aliases {
+			fmt.Fprintf(b, "### %s\n\n", a.Name)
+			code(b, a.Definition)
+			paragraph(b, a.Doc)
+		}
+	}
+
+	if len(m.Vars) > 0 {
+		b.WriteString("## Variables\n\n")
+		for _, v := range m.Vars {
+			fmt.Fprintf(b, "### %s\n\n", v.Name)
+			code(b, v.Declaration())
+			paragraph(b, v.Doc)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package dump prints the AST and symbol tables of each stage of the compiler, for debugging the compiler itself
+package dump
+
+import (
+	"bytes"
+	"compiler"
+	"error"
+	. "parser"
+	"printer"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Object is a node of a dump, Line and Column are 0 if the node has no position
+type Object struct {
+	Type   string
+	Line   int
+	Column int
+	Fields []Field
+}
+
+type Field struct {
+	Name  string
+	Value interface{} // Object, []interface{}, string, int or bool
+}
+
+// Stage is the dump of one stage of the compiler
+type Stage struct {
+	Name  string
+	Value interface{}
+}
+
+// Run runs the parser, analyzer and formatter on code and dumps the result of each,
+// when a stage fails the stages before it are returned with the error
+func Run(code []byte, path string) ([]Stage, *error.Error) {
+	stages := []Stage{}
+	compiler.NoEmit = true
+
+	err := error.Catch(func() {
+		ast := ParseFile(&Lexer{Buffer: code, Line: 1, Column: 1, Path: path})
+		stages = append(stages, Stage{"ast", Value(ast)})
+
+		symbols, imports, prefixes, _, num := compiler.AnalyzeFile(ast, path)
+		stages = append(stages, Stage{"symbols", Symbols(symbols)})
+
+		stages = append(stages, Stage{"formatted", Value(compiler.FormatFile(ast, symbols, imports, prefixes, num))})
+	})
+	return stages, err
+}
+
+var tokenType = reflect.TypeOf(Token{})
+var commentType = reflect.TypeOf(Comment{})
+
+// Value converts an AST node to a dump, fields with zero values are left out
+func Value(v interface{}) interface{} {
+	return value(reflect.ValueOf(v))
+}
+
+func value(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return value(v.Elem())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		list := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, value(v.Index(i)))
+		}
+		return list
+	case reflect.Map:
+		obj := Object{Type: "map"}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			obj.Fields = append(obj.Fields, Field{key.String(), value(v.MapIndex(key))})
+		}
+		return obj
+	case reflect.Struct:
+		switch v.Type() {
+		case tokenType:
+			return token(v.Interface().(Token))
+		case commentType:
+			comment := v.Interface().(Comment)
+			return Object{Type: "Comment", Line: comment.Line, Column: comment.Column, Fields: []Field{{"Text", string(comment.Text)}}}
+		}
+		return object(v)
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
+	case reflect.String:
+		return v.String()
+	}
+	return v.String()
+}
+
+func object(v reflect.Value) Object {
+	obj := Object{Type: v.Type().Name()}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		fv := v.Field(i)
+
+		if f.PkgPath != "" || isZero(fv) {
+			continue
+		}
+		switch f.Name {
+		case "Line":
+			obj.Line = int(fv.Int())
+			continue
+		case "Column":
+			obj.Column = int(fv.Int())
+			continue
+		}
+		obj.Fields = append(obj.Fields, Field{f.Name, value(fv)})
+	}
+	return obj
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+func token(tok Token) Object {
+	obj := Object{Type: "Token", Line: tok.Line, Column: tok.Column}
+	obj.Fields = append(obj.Fields, Field{"Value", string(tok.Buff)})
+	obj.Fields = append(obj.Fields, Field{"Kind", PrimaryTypes[tok.PrimaryType]})
+
+	if tok.SecondaryType != SecondaryNullType {
+		obj.Fields = append(obj.Fields, Field{"Secondary", SecondaryTypes[tok.SecondaryType]})
+	}
+	if tok.Flags != 0 {
+		obj.Fields = append(obj.Fields, Field{"Flags", tok.Flags})
+	}
+	return obj
+}
+
+// Symbols dumps a symbol table and its children, scopes are numbered in the order they were created
+func Symbols(t *compiler.SymbolTable) Object {
+	id := 0
+	return symbols(t, &id, -1)
+}
+
+func symbols(t *compiler.SymbolTable, id *int, parent int) Object {
+	obj := Object{Type: "SymbolTable", Fields: []Field{{"Scope", *id}}}
+	self := *id
+	*id++
+
+	if parent != -1 {
+		obj.Fields = append(obj.Fields, Field{"Parent", parent})
+	}
+	if t.Path != "" {
+		obj.Fields = append(obj.Fields, Field{"Path", t.Path})
+	}
+
+	nodes := []interface{}{}
+	for _, node := range t.Nodes {
+		nodes = append(nodes, Object{Type: "Symbol", Line: node.Identifier.Line, Column: node.Identifier.Column, Fields: []Field{
+			{"Name", string(node.Identifier.Buff)},
+			{"Type", typeString(node.Type)},
+		}})
+	}
+	if len(nodes) > 0 {
+		obj.Fields = append(obj.Fields, Field{"Nodes", nodes})
+	}
+
+	children := []interface{}{}
+	for _, child := range t.Children {
+		children = append(children, symbols(child, id, self))
+	}
+	if len(children) > 0 {
+		obj.Fields = append(obj.Fields, Field{"Children", children})
+	}
+	return obj
+}
+
+func typeString(typ Type) string {
+	switch typ.(type) {
+	case nil:
+		return ""
+	case Typedef:
+		return "typedef " + string(typ.(Typedef).Name.Buff)
+	case NumberType:
+		return "number"
+	case InternalType:
+		return "internal"
+	}
+	return string(printer.PrintType(typ))
+}
+
+// Text renders a dump as indented text, scalar fields are printed on the line of their node
+func Text(v interface{}) []byte {
+	b := &bytes.Buffer{}
+	text(b, v, "")
+	return b.Bytes()
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case Object, []interface{}:
+		return false
+	}
+	return true
+}
+
+func scalar(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return strconv.Quote(v.(string))
+	case nil:
+		return "nil"
+	}
+	return fmtValue(v)
+}
+
+func fmtValue(v interface{}) string {
+	switch v.(type) {
+	case int:
+		return strconv.Itoa(v.(int))
+	case bool:
+		return strconv.FormatBool(v.(bool))
+	}
+	return ""
+}
+
+// text writes v, its first line is expected to be already indented
+func text(b *bytes.Buffer, v interface{}, indent string) {
+	switch v.(type) {
+	case Object:
+		obj := v.(Object)
+		b.WriteString(obj.Type)
+		if obj.Line != 0 {
+			b.WriteString(" @" + strconv.Itoa(obj.Line) + ":" + strconv.Itoa(obj.Column))
+		}
+		for _, f := range obj.Fields {
+			if isScalar(f.Value) {
+				b.WriteString(" " + f.Name + "=" + scalar(f.Value))
+			}
+		}
+		b.WriteString("\n")
+
+		for _, f := range obj.Fields {
+			if isScalar(f.Value) {
+				continue
+			}
+			b.WriteString(indent + "  " + f.Name + ":")
+			switch f.Value.(type) {
+			case Object:
+				b.WriteString(" ")
+				text(b, f.Value, indent+"  ")
+			default:
+				b.WriteString("\n")
+				text(b, f.Value, indent+"    ")
+			}
+		}
+	case []interface{}:
+		for _, item := range v.([]interface{}) {
+			b.WriteString(indent + "- ")
+			if isScalar(item) {
+				b.WriteString(scalar(item) + "\n")
+				continue
+			}
+			text(b, item, indent+"  ")
+		}
+	default:
+		b.WriteString(indent + scalar(v) + "\n")
+	}
+}
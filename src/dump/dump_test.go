@@ -0,0 +1,60 @@
+package dump_test
+
+import (
+	"bytes"
+	"dump"
+	"error"
+	"internal/golden"
+	"io/ioutil"
+	"parser"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGolden dumps the tokens and the stages of every file in testdata, as text and as JSON, a stage that fails ends
+// the text dump with its error
+func TestGolden(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.vo")
+	if len(files) == 0 {
+		t.Fatal("no fixtures found")
+	}
+
+	for _, file := range files {
+		base := strings.TrimSuffix(file, ".vo")
+
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			code, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden.Check(t, base+".tokens", dump.Text(tokens(code, file)))
+
+			stages, dumpErr := dump.Run(code, file)
+			b := &bytes.Buffer{}
+			for _, stage := range stages {
+				b.WriteString("== " + stage.Name + " ==\n")
+				b.Write(dump.Text(stage.Value))
+			}
+			if dumpErr != nil {
+				b.WriteString("== error ==\n" + dumpErr.Error() + "\n")
+			}
+			golden.Check(t, base+".txt", b.Bytes())
+			golden.Check(t, base+".json", dump.JSON(stages))
+		})
+	}
+}
+
+// tokens dumps the tokens of code
+func tokens(code []byte, file string) []interface{} {
+	list := []interface{}{}
+	lexer := &parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: file}
+
+	error.Catch(func() {
+		for token := lexer.NextToken(); token.PrimaryType != parser.EOF; token = lexer.NextToken() {
+			list = append(list, dump.Value(token))
+		}
+	})
+	return list
+}
@@ -0,0 +1,49 @@
+package dump
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// MarshalJSON writes the type and position of the node before its fields, in order
+func (obj Object) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString(`{"type":`)
+	b.WriteString(strconv.Quote(obj.Type))
+
+	if obj.Line != 0 {
+		b.WriteString(`,"line":` + strconv.Itoa(obj.Line) + `,"column":` + strconv.Itoa(obj.Column))
+	}
+	for _, f := range obj.Fields {
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("," + strconv.Quote(f.Name) + ":")
+		b.Write(value)
+	}
+
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// JSON renders stages as an indented JSON object keyed by stage name
+func JSON(stages []Stage) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString("{")
+	for x, stage := range stages {
+		if x > 0 {
+			b.WriteString(",")
+		}
+		value, _ := json.Marshal(stage.Value)
+		b.WriteString(strconv.Quote(stage.Name) + ":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+
+	out := &bytes.Buffer{}
+	json.Indent(out, b.Bytes(), "", "  ")
+	out.WriteString("\n")
+	return out.Bytes()
+}
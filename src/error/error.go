@@ -7,11 +7,63 @@ import (
 
 type langError string
 
+// Error is an error reported by the lexer, parser, analyzer or importer
+// general (non-code) errors only have a Message
+type Error struct {
+	Path    string
+	Message string
+	Line    int
+	Column  int
+}
+
+func (err *Error) Error() string {
+	if err.Path == "" && err.Line == 0 {
+		return err.Message
+	}
+	if err.Path == "" {
+		return fmt.Sprintf("Error: line %d column %d: %s", err.Line, err.Column, err.Message)
+	}
+	return fmt.Sprintf("Error: line %d column %d: %s: %s", err.Line, err.Column, err.Path, err.Message)
+}
+
+// Handler is called with every error, the default prints it and exits
+// it must not return, callers assume compilation stops at the first error
+var Handler = func(err *Error) {
+	log.Fatal(err.Error())
+}
+
 func New(message string, line int, column int) {
-	log.Fatal(fmt.Sprintf("Error: line %d column %d: %s\n", line, column, message))
+	Handler(&Error{Message: message, Line: line, Column: column})
+}
+
+// NewFileError reports an error in the file at path
+func NewFileError(path string, message string, line int, column int) {
+	Handler(&Error{Path: path, Message: message, Line: line, Column: column})
 }
 
 // for general (non-code) errors
 func NewGenError(message string) {
-	log.Fatal(message)
+	Handler(&Error{Message: message})
+}
+
+// Catch runs f and returns the first error reported while it runs instead of exiting, nil if there was none
+func Catch(f func()) (err *Error) {
+	handler := Handler
+	Handler = func(err *Error) {
+		panic(err)
+	}
+
+	defer func() {
+		Handler = handler
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+
+	f()
+	return nil
 }
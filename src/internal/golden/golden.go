@@ -0,0 +1,34 @@
+// Package golden compares the output of tests with golden files, go test -update rewrites the files instead
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+// Check compares got with the golden file, or writes it with -update
+func Check(t *testing.T, file string, got []byte) {
+	t.Helper()
+
+	if *update {
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file:\n--- want\n%s\n--- got\n%s", file, want, got)
+	}
+}
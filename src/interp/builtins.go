@@ -0,0 +1,596 @@
+package interp
+
+import (
+	"fmt"
+	. "parser"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// vector is the memory of a vec, it is on the heap like the vectors of vector.h
+type vector struct {
+	addr     uint64
+	length   int
+	capacity int
+}
+
+// promise mirrors promise.h, it settles once and listeners added after that are called right away
+type promise struct {
+	state     promiseState
+	val       value
+	reason    value // a str
+	listeners []func()
+}
+
+type promiseState byte
+
+const (
+	promisePending promiseState = iota
+	promiseResolved
+	promiseRejected
+)
+
+func (p *promise) listen(l func()) {
+	if p.state != promisePending {
+		l()
+		return
+	}
+	p.listeners = append(p.listeners, l)
+}
+
+// settle resolves p with val or rejects it with reason, a settled promise stays as it is
+func (p *promise) settle(state promiseState, val value, reason value) {
+	if p.state != promisePending {
+		return
+	}
+	p.state, p.val, p.reason = state, val, reason
+
+	listeners := p.listeners
+	p.listeners = nil
+	for _, l := range listeners {
+		l()
+	}
+}
+
+// adopt settles p like from, which is settled
+func (p *promise) adopt(from *promise) {
+	p.settle(from.state, from.val, from.reason)
+}
+
+func (in *Interpreter) callExpr(expr CallExpr, sc *scope) value {
+	switch expr.Function.(type) {
+	case IdentExpr:
+		if name := string(expr.Function.(IdentExpr).Value.Buff); strings.HasPrefix(name, "$") {
+			return in.cCall(name[1:], in.args(expr.Args, sc), expr)
+		}
+	case MemberExpr:
+		// promise is a keyword, it is only parsed as an expression before the combinators
+		if base, ok := expr.Function.(MemberExpr).Base.(IdentExpr); ok && base.Value.PrimaryType == PromiseKeyword {
+			return in.combinator(string(expr.Function.(MemberExpr).Prop.Buff), in.args(expr.Args, sc), expr)
+		}
+		if in.moduleOf(expr.Function.(MemberExpr).Base, sc) == nil {
+			return in.methodCall(expr.Function.(MemberExpr), expr, sc)
+		}
+	}
+
+	fn := in.eval(expr.Function, sc)
+	if fn.typ.kind == pointerKind && fn.typ.elem.kind == funcKind {
+		fn = in.load(fn.i, fn.typ.elem, expr)
+	}
+	if fn.typ.kind != funcKind {
+		in.error("Expression of type "+fn.typ.String()+" is not a function.", expr)
+	}
+	return in.call(in.closure(fn, expr), in.args(expr.Args, sc), expr)
+}
+
+func (in *Interpreter) args(exprs []Expression, sc *scope) []value {
+	args := make([]value, len(exprs))
+	for x, expr := range exprs {
+		args[x] = in.eval(expr, sc)
+	}
+	return args
+}
+
+// methodCall calls a method of a struct, a vector, a promise or a str, or a function stored in a field
+// methods whose first parameter is the struct or a pointer to it are passed the base as self, like the analyzer does
+func (in *Interpreter) methodCall(fn MemberExpr, expr CallExpr, sc *scope) value {
+	name := string(fn.Prop.Buff)
+	addr, t, v := in.base(fn.Base, sc)
+
+	if t == strType {
+		return in.strMethod(addr, name, in.args(expr.Args, sc), expr)
+	}
+
+	switch t.kind {
+	case structKind, unionKind, tupleKind:
+		if c, ok := t.methods[name]; ok {
+			args := in.args(expr.Args, sc)
+
+			if ct := in.closureType(c); len(ct.args) > 0 {
+				switch self := ct.args[0]; {
+				case self.kind == structKind:
+					args = append([]value{in.load(addr, t, expr)}, args...)
+				case self.kind == pointerKind && self.elem.kind == structKind:
+					args = append([]value{{typ: pointerTo(t), i: addr}}, args...)
+				}
+			}
+			return in.call(c, args, expr)
+		}
+		if f, ok := t.field(name); ok && f.typ.kind == funcKind {
+			return in.call(in.closure(in.load(addr+uint64(f.offset), f.typ, expr), expr), in.args(expr.Args, sc), expr)
+		}
+	case vecKind:
+		return in.vectorMethod(v, name, in.args(expr.Args, sc), expr)
+	case promiseKind:
+		return in.promiseMethod(v, name, in.args(expr.Args, sc), expr)
+	}
+	in.error(t.String()+" has no method called '"+name+"'.", expr)
+	return void
+}
+
+func (in *Interpreter) vector(v value, node positioned) *vector {
+	vec, ok := in.object(v.i).(*vector)
+	if !ok {
+		in.error("Use of an uninitialized vector.", node)
+	}
+	return vec
+}
+
+func (in *Interpreter) promise(v value, node positioned) *promise {
+	prom, ok := in.object(v.i).(*promise)
+	if !ok {
+		in.error("Use of an uninitialized promise.", node)
+	}
+	return prom
+}
+
+// newVector returns a vector holding elems, with a capacity of at least 8 like _vector_new
+func (in *Interpreter) newVector(t *rtype, elems []value) value {
+	vec := &vector{}
+	in.resize(vec, t.elem, 8)
+	in.resize(vec, t.elem, len(elems))
+
+	for x, el := range elems {
+		el.encode(in.mem.bytes(vec.addr+uint64(x*t.elem.size), t.elem.size))
+	}
+	vec.length = len(elems)
+	return value{typ: t, i: in.handle(vec)}
+}
+
+// resize grows the memory of vec to hold capacity elements, it never shrinks
+func (in *Interpreter) resize(vec *vector, elem *rtype, capacity int) {
+	if capacity <= vec.capacity {
+		return
+	}
+	addr := in.alloc(arrayOf(elem, capacity), NullStatement{})
+	if vec.capacity > 0 {
+		copy(in.mem.bytes(addr, vec.length*elem.size), in.mem.bytes(vec.addr, vec.length*elem.size))
+	}
+	vec.addr, vec.capacity = addr, capacity
+}
+
+func (in *Interpreter) vectorMethod(v value, name string, args []value, node positioned) value {
+	vec, elem := in.vector(v, node), v.typ.elem
+
+	switch name {
+	case "push":
+		in.arity(args, 1, node)
+		if vec.length == vec.capacity {
+			in.resize(vec, elem, vec.capacity+8)
+		}
+		el := in.convert(args[0], elem, node)
+		in.store(vec.addr+uint64(vec.length*elem.size), el, node)
+		vec.length++
+		return el
+	case "pop":
+		in.arity(args, 0, node)
+		if vec.length == 0 {
+			in.error("Pop from an empty vector.", node)
+		}
+		vec.length--
+		return in.load(vec.addr+uint64(vec.length*elem.size), elem, node)
+	case "concat":
+		in.arity(args, 1, node)
+		other := in.vector(args[0], node)
+		in.resize(vec, elem, vec.length+other.length)
+		copy(in.mem.bytes(vec.addr+uint64(vec.length*elem.size), other.length*elem.size), in.mem.bytes(other.addr, other.length*elem.size))
+		vec.length += other.length
+		return v
+	case "clone":
+		in.arity(args, 0, node)
+		elems := make([]value, vec.length)
+		for x := range elems {
+			elems[x] = in.load(vec.addr+uint64(x*elem.size), elem, node)
+		}
+		return in.newVector(v.typ, elems)
+	case "free":
+		in.arity(args, 0, node)
+		return void
+	}
+	in.error("Vectors have no method called '"+name+"'.", node)
+	return void
+}
+
+func (in *Interpreter) newPromise(t *rtype) (value, *promise) {
+	prom := &promise{}
+	return value{typ: t, i: in.handle(prom)}, prom
+}
+
+func (in *Interpreter) promiseMethod(v value, name string, args []value, node positioned) value {
+	prom := in.promise(v, node)
+	in.arity(args, 1, node)
+
+	switch name {
+	case "then":
+		c := in.closure(args[0], node)
+		ret := in.closureType(c).ret
+
+		switch ret.kind {
+		case voidKind:
+			prom.listen(func() {
+				if prom.state == promiseResolved {
+					in.call(c, []value{prom.val}, node)
+				}
+			})
+			return v
+		case promiseKind:
+			// the promise the callback returns is followed
+			nv, next := in.newPromise(ret)
+			prom.listen(func() {
+				if prom.state != promiseResolved {
+					next.adopt(prom)
+					return
+				}
+				inner := in.promise(in.call(c, []value{prom.val}, node), node)
+				inner.listen(func() {
+					next.adopt(inner)
+				})
+			})
+			return nv
+		}
+		nv, next := in.newPromise(promiseOf(ret))
+		prom.listen(func() {
+			if prom.state != promiseResolved {
+				next.adopt(prom)
+				return
+			}
+			next.settle(promiseResolved, in.call(c, []value{prom.val}, node), void)
+		})
+		return nv
+	case "catch":
+		c := in.closure(args[0], node)
+		prom.listen(func() {
+			if prom.state == promiseRejected {
+				in.call(c, []value{prom.reason}, node)
+			}
+		})
+		return v
+	case "finally":
+		c := in.closure(args[0], node)
+		prom.listen(func() {
+			in.call(c, nil, node)
+		})
+		return v
+	case "resolve":
+		prom.settle(promiseResolved, in.convert(args[0], v.typ.elem, node), void)
+		return void
+	case "reject":
+		prom.settle(promiseRejected, void, in.convert(args[0], strType, node))
+		return void
+	}
+	in.error("Promises have no method called '"+name+"'.", node)
+	return void
+}
+
+// noPromises is the reason promise.any is rejected with when it is given no promises, like in promise.h
+var noPromises = Token{Buff: []byte(`"no promises"`), PrimaryType: StringLiteral}
+
+// combinator calls promise.all, promise.race or promise.any with a vector of promises
+func (in *Interpreter) combinator(name string, args []value, node positioned) value {
+	in.arity(args, 1, node)
+	if args[0].typ.kind != vecKind || args[0].typ.elem.kind != promiseKind {
+		in.error("promise."+name+" takes a vector of promises, got "+args[0].typ.String()+".", node)
+	}
+	vec, t := in.vector(args[0], node), args[0].typ.elem
+
+	proms := make([]*promise, vec.length)
+	for x := range proms {
+		proms[x] = in.promise(in.load(vec.addr+uint64(x*t.size), t, node), node)
+	}
+
+	switch name {
+	case "all":
+		// the values are in the order of the promises, the first rejection rejects it
+		nv, next := in.newPromise(promiseOf(vecOf(t.elem)))
+		vals := make([]value, len(proms))
+		left := len(proms)
+		if left == 0 {
+			next.settle(promiseResolved, in.newVector(vecOf(t.elem), vals), void)
+		}
+		for x, p := range proms {
+			x, p := x, p
+			p.listen(func() {
+				if p.state == promiseRejected {
+					next.adopt(p)
+					return
+				}
+				vals[x] = p.val
+				if left--; left == 0 {
+					next.settle(promiseResolved, in.newVector(vecOf(t.elem), vals), void)
+				}
+			})
+		}
+		return nv
+	case "race":
+		nv, next := in.newPromise(t)
+		for _, p := range proms {
+			p := p
+			p.listen(func() {
+				next.adopt(p)
+			})
+		}
+		return nv
+	case "any":
+		// it is rejected with the last reason when every promise is rejected
+		nv, next := in.newPromise(t)
+		left := len(proms)
+		if left == 0 {
+			next.settle(promiseRejected, void, in.convert(in.literal(noPromises, node), strType, node))
+		}
+		for _, p := range proms {
+			p := p
+			p.listen(func() {
+				if p.state == promiseResolved {
+					next.adopt(p)
+				} else if left--; left == 0 {
+					next.adopt(p)
+				}
+			})
+		}
+		return nv
+	}
+	in.error("promise has no function called '"+name+"'.", node)
+	return void
+}
+
+func (in *Interpreter) arity(args []value, n int, node positioned) {
+	if len(args) != n {
+		in.error("Expected "+strconv.Itoa(n)+" arguments, got "+strconv.Itoa(len(args))+".", node)
+	}
+}
+
+// cString reads the string a pointer points to
+func (in *Interpreter) cString(v value, node positioned) []byte {
+	v = in.decay(v, node)
+	if v.typ.kind != pointerKind && v.typ.kind != intKind {
+		in.error("Expected a string, got "+v.typ.String()+".", node)
+	}
+	str, ok := in.mem.cString(v.i)
+	if !ok {
+		in.error("Invalid memory access at 0x"+strconv.FormatUint(v.i, 16)+".", node)
+	}
+	return str
+}
+
+// decimal matches the numbers strtod parses, hexadecimal floats, infinities and nans are left out
+var decimal = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?`)
+
+// cCall calls the functions of the C standard library that programs and the standard library use
+func (in *Interpreter) cCall(name string, args []value, node positioned) value {
+	for x := range args {
+		args[x] = in.decay(args[x], node)
+	}
+
+	switch name {
+	case "printf":
+		if len(args) == 0 {
+			in.error("Expected a format string.", node)
+		}
+		out := in.printf(in.cString(args[0], node), args[1:], node)
+		in.Stdout.Write(out)
+		return intValue(i32Type, uint64(len(out)))
+	case "snprintf":
+		if len(args) < 3 {
+			in.error("Expected a buffer, a size and a format string.", node)
+		}
+		out := in.printf(in.cString(args[2], node), args[3:], node)
+		if n := args[1].i; n > 0 {
+			if uint64(len(out)) >= n {
+				copy(in.bytes(args[0].i, n, node), append(out[:n-1], 0))
+			} else {
+				copy(in.bytes(args[0].i, uint64(len(out))+1, node), append(out, 0))
+			}
+		}
+		return intValue(i32Type, uint64(len(out)))
+	case "puts":
+		in.arity(args, 1, node)
+		in.Stdout.Write(in.cString(args[0], node))
+		in.Stdout.WriteByte('\n')
+		return intValue(i32Type, 1)
+	case "putchar":
+		in.arity(args, 1, node)
+		in.Stdout.WriteByte(byte(args[0].i))
+		return intValue(i32Type, uint64(byte(args[0].i)))
+	case "getchar":
+		in.arity(args, 0, node)
+		in.Stdout.Flush()
+		c, err := in.Stdin.ReadByte()
+		if err != nil {
+			return intValue(i32Type, ^uint64(0))
+		}
+		return intValue(i32Type, uint64(c))
+	case "malloc", "calloc":
+		size := uint64(1)
+		for _, arg := range args {
+			size *= arg.i
+		}
+		return in.malloc(size, node)
+	case "realloc":
+		in.arity(args, 2, node)
+		p := in.malloc(args[1].i, node)
+		if old, ok := in.mem.sizes[args[0].i]; ok {
+			if uint64(old) > args[1].i {
+				old = int(args[1].i)
+			}
+			copy(in.mem.bytes(p.i, old), in.mem.bytes(args[0].i, old))
+		}
+		return p
+	case "free", "Block_release":
+		return void
+	case "_Block_copy":
+		in.arity(args, 1, node)
+		return args[0]
+	case "memcpy", "memmove":
+		in.arity(args, 3, node)
+		src := in.bytes(args[1].i, args[2].i, node)
+		copy(in.bytes(args[0].i, args[2].i, node), append([]byte{}, src...))
+		return args[0]
+	case "memset":
+		in.arity(args, 3, node)
+		b := in.bytes(args[0].i, args[2].i, node)
+		for x := range b {
+			b[x] = byte(args[1].i)
+		}
+		return args[0]
+	case "strlen":
+		in.arity(args, 1, node)
+		return value{typ: sizeType, i: uint64(len(in.cString(args[0], node)))}
+	case "strcmp":
+		in.arity(args, 2, node)
+		return intValue(i32Type, uint64(strings.Compare(string(in.cString(args[0], node)), string(in.cString(args[1], node)))))
+	case "strtod":
+		in.arity(args, 2, node)
+		str := in.cString(args[0], node)
+		n := len(str) - len(strings.TrimLeft(string(str), " \t\n\v\f\r"))
+		end := n + len(decimal.Find(str[n:]))
+
+		f, _ := strconv.ParseFloat(string(str[n:end]), 64)
+		if end == n {
+			end = 0
+		}
+		if args[1].i != 0 {
+			in.store(args[1].i, value{typ: pointerTo(u8Type), i: args[0].i + uint64(end)}, node)
+		}
+		return floatValue(f64Type, f)
+	case "exit":
+		in.arity(args, 1, node)
+		panic(exit(int32(args[0].i)))
+	case "abort":
+		in.Stdout.Flush()
+		in.error("Program aborted.", node)
+	}
+	in.error("C function '$"+name+"' is not available in the interpreter.", node)
+	return void
+}
+
+func (in *Interpreter) bytes(addr uint64, size uint64, node positioned) []byte {
+	b := in.mem.bytes(addr, int(size))
+	if b == nil || size > maxHeap {
+		in.error("Invalid memory access at 0x"+strconv.FormatUint(addr, 16)+".", node)
+	}
+	return b
+}
+
+func (in *Interpreter) malloc(size uint64, node positioned) value {
+	if size > maxHeap {
+		return value{typ: voidPointer}
+	}
+	addr := in.mem.alloc(int(size), 16)
+	if in.mem.sizes == nil {
+		in.mem.sizes = map[uint64]int{}
+	}
+	in.mem.sizes[addr] = int(size)
+	return value{typ: voidPointer, i: addr}
+}
+
+// printf formats args like C's printf, the conversions are translated to the ones of fmt
+func (in *Interpreter) printf(format []byte, args []value, node positioned) []byte {
+	out := []byte{}
+	next := func() value {
+		if len(args) == 0 {
+			in.error("Too few arguments for the format string.", node)
+		}
+		v := args[0]
+		args = args[1:]
+		return v
+	}
+
+	for x := 0; x < len(format); x++ {
+		if format[x] != '%' {
+			out = append(out, format[x])
+			continue
+		}
+		spec := []byte{'%'}
+		x++
+
+		for ; x < len(format) && strings.IndexByte("-+ #0", format[x]) >= 0; x++ {
+			spec = append(spec, format[x])
+		}
+		for ; x < len(format) && (format[x] >= '0' && format[x] <= '9' || format[x] == '.' || format[x] == '*'); x++ {
+			if format[x] == '*' {
+				spec = strconv.AppendInt(spec, next().int(), 10)
+				continue
+			}
+			spec = append(spec, format[x])
+		}
+		size := 4
+		for ; x < len(format) && strings.IndexByte("hlzjtL", format[x]) >= 0; x++ {
+			switch format[x] {
+			case 'h':
+				size /= 2
+			default:
+				size = 8
+			}
+		}
+		if x == len(format) {
+			break
+		}
+
+		switch c := format[x]; c {
+		case '%':
+			out = append(out, '%')
+		case 'd', 'i':
+			v := next()
+			n := int64(v.i)
+			switch size {
+			case 1:
+				n = int64(int8(n))
+			case 2:
+				n = int64(int16(n))
+			case 4:
+				n = int64(int32(n))
+			}
+			out = append(out, fmt.Sprintf(string(spec)+"d", n)...)
+		case 'u', 'x', 'X', 'o':
+			n := next().i
+			if size < 8 {
+				n &= 1<<(uint(size)*8) - 1
+			}
+			if c == 'u' {
+				c = 'd'
+			}
+			out = append(out, fmt.Sprintf(string(spec)+string(c), n)...)
+		case 'c':
+			out = append(out, byte(next().i))
+		case 's':
+			out = append(out, fmt.Sprintf(string(spec)+"s", in.cString(next(), node))...)
+		case 'p':
+			out = append(out, fmt.Sprintf("0x%x", next().i)...)
+		case 'f', 'F', 'e', 'E', 'g', 'G':
+			v := next()
+			f := v.f
+			if v.typ.kind == intKind {
+				f = float64(int64(v.i))
+			}
+			// %g without a precision prints 6 significant digits in C and as many as needed in fmt
+			if (c == 'g' || c == 'G') && !strings.Contains(string(spec), ".") {
+				spec = append(spec, ".6"...)
+			}
+			out = append(out, fmt.Sprintf(string(spec)+string(c), f)...)
+		default:
+			in.error("Unsupported conversion '%"+string(c)+"' in format string.", node)
+		}
+	}
+	return out
+}
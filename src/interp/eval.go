@@ -0,0 +1,674 @@
+package interp
+
+import (
+	"compiler"
+	. "parser"
+	"strconv"
+	"strings"
+)
+
+// eval evaluates expr in sc, arrays are loaded with their address so that they can decay to pointers
+func (in *Interpreter) eval(expr Expression, sc *scope) value {
+	switch expr.(type) {
+	case BasicLit:
+		return in.literal(expr.(BasicLit).Value, expr)
+	case IdentExpr:
+		name := string(expr.(IdentExpr).Value.Buff)
+
+		switch b := sc.lookup(name); b.(type) {
+		case *variable:
+			return in.load(b.(*variable).addr, b.(*variable).typ, expr)
+		case *constant:
+			return b.(*constant).val
+		case nil:
+			if strings.HasPrefix(name, "$") {
+				in.error("C identifier '"+name+"' is not available in the interpreter.", expr)
+			}
+			in.error("Use of undeclared variable '"+name+"'.", expr)
+		}
+		in.error("'"+name+"' is not a value.", expr)
+	case MemberExpr:
+		return in.member(expr.(MemberExpr), sc)
+	case ArrayMemberExpr, PointerMemberExpr:
+		addr, t, _ := in.lvalue(expr, sc)
+		return in.load(addr, t, expr)
+	case UnaryExpr:
+		return in.unary(expr.(UnaryExpr), sc)
+	case PostfixUnaryExpr:
+		if expr.(PostfixUnaryExpr).Op.SecondaryType == SubSub {
+			return in.incr(expr.(PostfixUnaryExpr).Expr, -1, false, sc)
+		}
+		return in.incr(expr.(PostfixUnaryExpr).Expr, 1, false, sc)
+	case BinaryExpr:
+		bin := expr.(BinaryExpr)
+
+		switch bin.Op.SecondaryType {
+		case AndAnd:
+			return boolValue(in.truthy(in.eval(bin.Left, sc), bin.Left) && in.truthy(in.eval(bin.Right, sc), bin.Right))
+		case OrOr:
+			return boolValue(in.truthy(in.eval(bin.Left, sc), bin.Left) || in.truthy(in.eval(bin.Right, sc), bin.Right))
+		}
+		return in.binary(bin.Op, in.decay(in.eval(bin.Left, sc), bin.Left), in.decay(in.eval(bin.Right, sc), bin.Right), expr)
+	case TernaryExpr:
+		if in.truthy(in.eval(expr.(TernaryExpr).Cond, sc), expr) {
+			return in.eval(expr.(TernaryExpr).Left, sc)
+		}
+		return in.eval(expr.(TernaryExpr).Right, sc)
+	case TypeCast:
+		return in.convert(in.eval(expr.(TypeCast).Expr, sc), in.resolve(expr.(TypeCast).Type, sc), expr)
+	case CallExpr:
+		return in.callExpr(expr.(CallExpr), sc)
+	case FuncExpr:
+		c := &closure{fnc: expr.(FuncExpr), env: in.snapshot(sc), path: in.path}
+		return value{typ: in.closureType(c), i: in.handle(c)}
+	case CompoundLiteral:
+		return in.compound(expr.(CompoundLiteral), sc)
+	case ArrayLiteral:
+		v := value{typ: listType}
+		for _, el := range expr.(ArrayLiteral).Exprs {
+			v.list = append(v.list, in.eval(el, sc))
+		}
+		return v
+	case HeapAlloc:
+		return in.heapAlloc(expr.(HeapAlloc), sc)
+	case InterpolatedString:
+		return in.interpolate(expr.(InterpolatedString), sc)
+	case LenExpr:
+		return in.length(expr.(LenExpr), sc)
+	case SizeExpr:
+		return value{typ: sizeType, i: uint64(in.sizeof(expr.(SizeExpr).Expr, sc).size)}
+	case Type:
+		in.error("Expected an expression, got a type.", expr)
+	}
+	in.error("Unsupported expression.", expr)
+	return void
+}
+
+// literal evaluates a literal like C does, integers are int unless they do not fit and floats are double
+func (in *Interpreter) literal(tok Token, node positioned) value {
+	switch tok.PrimaryType {
+	case CharLiteral:
+		n, _ := strconv.ParseInt(string(tok.Buff), 10, 64)
+		return intValue(i32Type, uint64(n))
+	case StringLiteral:
+		str := tok.Buff[1 : len(tok.Buff)-1]
+		if tok.SecondaryType != RawString {
+			str = Unescape(str)
+		}
+		t := arrayOf(u8Type, len(str)+1)
+
+		addr, ok := in.strings[&tok.Buff[0]]
+		if !ok {
+			addr = in.alloc(t, node)
+			copy(in.mem.bytes(addr, len(str)), str)
+			in.strings[&tok.Buff[0]] = addr
+		}
+		return in.load(addr, t, node)
+	}
+
+	num := string(tok.Buff)
+	base := 10
+
+	switch tok.SecondaryType {
+	case BinaryRadix:
+		num, base = strings.TrimPrefix(num, "0b"), 2
+	case OctalRadix:
+		base = 8
+	case HexadecimalRadix:
+		num, base = strings.TrimPrefix(num, "0x"), 16
+	}
+
+	if base == 10 && strings.Contains(num, ".") {
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			in.error("Invalid number literal '"+string(tok.Buff)+"'.", node)
+		}
+		return value{typ: f64Type, f: f}
+	}
+
+	n, err := strconv.ParseUint(num, base, 64)
+	switch {
+	case err != nil:
+		in.error("Invalid number literal '"+string(tok.Buff)+"'.", node)
+	case n <= 1<<31-1:
+		return value{typ: i32Type, i: n}
+	case n <= 1<<63-1:
+		return value{typ: i64Type, i: n}
+	}
+	return value{typ: u64Type, i: n}
+}
+
+// interpolate evaluates the expressions of str once, in order, into hidden variables,
+// then builds the string with string.vo like compiled code does
+func (in *Interpreter) interpolate(str InterpolatedString, sc *scope) value {
+	sc = sc.child()
+	kinds := make([]string, len(str.Exprs))
+	str.Exprs = append([]Expression{}, str.Exprs...)
+
+	for x, expr := range str.Exprs {
+		v := in.eval(expr, sc)
+		t := in.declType(v, expr, sc)
+		v = in.convert(v, t, expr)
+
+		if kinds[x] = interpolationKind(v.typ); kinds[x] == "" {
+			in.error("Cannot interpolate "+v.typ.name+", only numbers, bools, pointers and strings can be.", expr)
+		}
+
+		b := &variable{typ: v.typ}
+		if sc.local {
+			b.addr = in.push(v.typ, expr)
+		} else {
+			b.addr = in.alloc(v.typ, expr)
+		}
+		in.store(b.addr, v, expr)
+
+		// the space keeps the name from clashing with names in the program
+		name := " " + strconv.Itoa(x)
+		sc.names[name] = b
+		tok := Token{Buff: []byte(name), PrimaryType: Identifier, Line: expr.LineM(), Column: expr.ColumnM()}
+		str.Exprs[x] = IdentExpr{Value: tok, Line: tok.Line, Column: tok.Column}
+	}
+
+	x := -1
+	return in.eval(compiler.Interpolate(str, func(Expression) string {
+		x++
+		return kinds[x]
+	}), sc)
+}
+
+// interpolationKind returns the append function of string.vo that values of type t are interpolated with
+func interpolationKind(t *rtype) string {
+	switch t.kind {
+	case intKind:
+		switch {
+		case t == boolType:
+			return compiler.BoolKind
+		case t.signed || t.values != nil:
+			return compiler.IntKind
+		}
+		return compiler.UintKind
+	case floatKind:
+		return compiler.FloatKind
+	case pointerKind, arrayKind:
+		if t.elem.kind == intKind && t.elem.size == 1 && t.elem != boolType {
+			return compiler.CStringKind
+		}
+		if t.kind == pointerKind {
+			return compiler.PointerKind
+		}
+	case structKind:
+		if t == strType {
+			return compiler.StrKind
+		}
+		if t.name == "String" {
+			return compiler.StringKind
+		}
+	}
+	return ""
+}
+
+// decay converts arrays to pointers to their first element, like C does when they are used as values
+func (in *Interpreter) decay(v value, node positioned) value {
+	if v.typ.kind != arrayKind {
+		return v
+	}
+	return value{typ: pointerTo(v.typ.elem), i: in.temp(v, node)}
+}
+
+// sizeof returns the type of expr without evaluating it when it names a type
+func (in *Interpreter) sizeof(expr Expression, sc *scope) *rtype {
+	switch expr.(type) {
+	case IdentExpr:
+		if t, ok := sc.lookup(string(expr.(IdentExpr).Value.Buff)).(*typeName); ok {
+			return t.typ
+		}
+		if strings.HasPrefix(string(expr.(IdentExpr).Value.Buff), "$") {
+			return in.namedType(expr, sc)
+		}
+	case Type:
+		return in.resolve(expr.(Type), sc)
+	}
+	return in.eval(expr, sc).typ
+}
+
+// lvalue returns the address and the type of an expression that designates memory, ok is false for other expressions
+func (in *Interpreter) lvalue(expr Expression, sc *scope) (uint64, *rtype, bool) {
+	switch expr.(type) {
+	case IdentExpr:
+		if v, ok := sc.lookup(string(expr.(IdentExpr).Value.Buff)).(*variable); ok {
+			return v.addr, v.typ, true
+		}
+	case MemberExpr:
+		return in.field(expr.(MemberExpr).Base, expr.(MemberExpr).Prop, sc, expr)
+	case PointerMemberExpr:
+		return in.field(expr.(PointerMemberExpr).Base, expr.(PointerMemberExpr).Prop, sc, expr)
+	case ArrayMemberExpr:
+		addr, t := in.index(expr.(ArrayMemberExpr), sc)
+		return addr, t, true
+	case UnaryExpr:
+		if expr.(UnaryExpr).Op.SecondaryType != Mul {
+			break
+		}
+		p := in.decay(in.eval(expr.(UnaryExpr).Expr, sc), expr)
+		if p.typ.kind != pointerKind {
+			in.error("Cannot dereference a value of type "+p.typ.String()+".", expr)
+		}
+		if p.typ.elem.kind == voidKind {
+			in.error("Cannot dereference a void pointer.", expr)
+		}
+		return p.i, p.typ.elem, true
+	}
+	return 0, nil, false
+}
+
+// base evaluates the base of a member expression, pointers are followed and aggregates are given an address
+func (in *Interpreter) base(expr Expression, sc *scope) (uint64, *rtype, value) {
+	var v value
+
+	if addr, t, ok := in.lvalue(expr, sc); ok {
+		if !t.isScalar() {
+			return addr, t, void
+		}
+		v = in.load(addr, t, expr)
+	} else {
+		v = in.eval(expr, sc)
+		if !v.typ.isScalar() {
+			return in.temp(v, expr), v.typ, void
+		}
+	}
+
+	if v.typ.kind == pointerKind {
+		switch elem := v.typ.elem; {
+		case !elem.isScalar():
+			return v.i, elem, void
+		case elem.kind == vecKind || elem.kind == promiseKind:
+			return v.i, elem, in.load(v.i, elem, expr)
+		}
+	}
+	return 0, v.typ, v
+}
+
+// field returns the address of a member of a struct, a union or a module
+func (in *Interpreter) field(base Expression, prop Token, sc *scope, node positioned) (uint64, *rtype, bool) {
+	name := string(prop.Buff)
+
+	if m := in.moduleOf(base, sc); m != nil {
+		v, ok := m.scope.names[name].(*variable)
+		if !ok {
+			in.error("'"+name+"' is not a variable of the module.", node)
+		}
+		return v.addr, v.typ, true
+	}
+
+	addr, t, _ := in.base(base, sc)
+
+	switch t.kind {
+	case structKind, unionKind, tupleKind:
+		if f, ok := t.field(name); ok {
+			return addr + uint64(f.offset), f.typ, true
+		}
+	}
+	in.error(t.String()+" has no member called '"+name+"'.", node)
+	return 0, nil, false
+}
+
+// member evaluates a member of a struct, a union, a module, an enum, a vector or a promise
+func (in *Interpreter) member(expr MemberExpr, sc *scope) value {
+	name := string(expr.Prop.Buff)
+
+	if m := in.moduleOf(expr.Base, sc); m != nil {
+		switch b := m.scope.names[name]; b.(type) {
+		case *variable:
+			return in.load(b.(*variable).addr, b.(*variable).typ, expr)
+		case nil:
+			in.error("'"+name+"' is not exported from '"+string(expr.Base.(IdentExpr).Value.Buff)+"'.", expr)
+		}
+		in.error("'"+name+"' is not a value.", expr)
+	}
+
+	switch expr.Base.(type) {
+	case IdentExpr:
+		if t, ok := sc.lookup(string(expr.Base.(IdentExpr).Value.Buff)).(*typeName); ok {
+			return in.enumMember(t.typ, name, expr)
+		}
+	case MemberExpr:
+		// enums exported from modules are named module.Enum.Member
+		base := expr.Base.(MemberExpr)
+		if m := in.moduleOf(base.Base, sc); m != nil {
+			if t, ok := m.scope.names[string(base.Prop.Buff)].(*typeName); ok {
+				return in.enumMember(t.typ, name, expr)
+			}
+		}
+	}
+
+	addr, t, v := in.base(expr.Base, sc)
+
+	switch t.kind {
+	case structKind, unionKind, tupleKind:
+		if f, ok := t.field(name); ok {
+			return in.load(addr+uint64(f.offset), f.typ, expr)
+		}
+	case vecKind:
+		vec := in.vector(v, expr)
+		switch name {
+		case "length":
+			return value{typ: sizeType, i: uint64(vec.length)}
+		case "capacity":
+			return value{typ: sizeType, i: uint64(vec.capacity)}
+		}
+	case promiseKind:
+		prom := in.promise(v, expr)
+		switch name {
+		case "pending":
+			return boolValue(prom.state == promisePending)
+		case "resolved":
+			return boolValue(prom.state == promiseResolved)
+		case "rejected":
+			return boolValue(prom.state == promiseRejected)
+		}
+	}
+	in.error(t.String()+" has no member called '"+name+"'.", expr)
+	return void
+}
+
+func (in *Interpreter) enumMember(t *rtype, name string, node positioned) value {
+	n, ok := t.values[name]
+	if !ok {
+		in.error("Enum "+t.String()+" has no member called '"+name+"'.", node)
+	}
+	return intValue(t, uint64(n))
+}
+
+// index returns the address of an element of an array, a pointer, a vector, a tuple or a byte of a str
+func (in *Interpreter) index(expr ArrayMemberExpr, sc *scope) (uint64, *rtype) {
+	var addr uint64
+	var t *rtype
+
+	if a, at, ok := in.lvalue(expr.Parent, sc); ok && (at.kind == arrayKind || at.kind == tupleKind || at == strType) {
+		addr, t = a, at
+	} else if ok {
+		v := in.load(a, at, expr)
+		addr, t = v.i, v.typ
+	} else {
+		v := in.eval(expr.Parent, sc)
+		addr, t = v.i, v.typ
+		if !v.typ.isScalar() {
+			addr = in.temp(v, expr)
+		}
+	}
+
+	if t.kind == tupleKind {
+		f, ok := t.field(string(literalBuff(expr.Index)))
+		if !ok {
+			in.error("Tuple index must be a number from 0 to "+strconv.Itoa(len(t.fields)-1)+".", expr.Index)
+		}
+		return addr + uint64(f.offset), f.typ
+	}
+
+	i := in.eval(expr.Index, sc)
+	if i.typ.kind != intKind {
+		in.error("Index must be an integer, got "+i.typ.String()+".", expr.Index)
+	}
+	n := i.int()
+
+	if t == strType {
+		return in.strIndex(addr, n, expr), u8Type
+	}
+
+	switch t.kind {
+	case arrayKind:
+		if n < 0 || t.length >= 0 && n >= int64(t.length) {
+			in.error("Index "+strconv.FormatInt(n, 10)+" out of range for "+t.String()+".", expr)
+		}
+		return addr + uint64(n*int64(t.elem.size)), t.elem
+	case pointerKind:
+		if t.elem.kind == voidKind {
+			in.error("Cannot index a void pointer.", expr)
+		}
+		return addr + uint64(n*int64(t.elem.size)), t.elem
+	case vecKind:
+		vec := in.vector(value{typ: t, i: addr}, expr)
+		if n < 0 || n >= int64(vec.length) {
+			in.error("Index "+strconv.FormatInt(n, 10)+" out of range for a vector of length "+strconv.Itoa(vec.length)+".", expr)
+		}
+		return vec.addr + uint64(n*int64(t.elem.size)), t.elem
+	}
+	in.error("Cannot index a value of type "+t.String()+".", expr)
+	return 0, nil
+}
+
+func literalBuff(expr Expression) []byte {
+	switch expr.(type) {
+	case BasicLit:
+		return expr.(BasicLit).Value.Buff
+	}
+	return nil
+}
+
+func (in *Interpreter) unary(expr UnaryExpr, sc *scope) value {
+	switch expr.Op.SecondaryType {
+	case Mul:
+		addr, t, _ := in.lvalue(expr, sc)
+		return in.load(addr, t, expr)
+	case And:
+		addr, t, ok := in.lvalue(expr.Expr, sc)
+		if !ok {
+			in.error("Cannot take the address of an expression that is not a variable.", expr)
+		}
+		return value{typ: pointerTo(t), i: addr}
+	case AddAdd:
+		return in.incr(expr.Expr, 1, true, sc)
+	case SubSub:
+		return in.incr(expr.Expr, -1, true, sc)
+	}
+
+	v := in.eval(expr.Expr, sc)
+
+	switch expr.Op.SecondaryType {
+	case Not:
+		return boolValue(!in.truthy(in.decay(v, expr), expr))
+	case Add, Sub, BitwiseNot:
+		if v.typ.kind != intKind && v.typ.kind != floatKind {
+			in.error("Invalid operand "+v.typ.String()+" for '"+string(expr.Op.Buff)+"'.", expr)
+		}
+		t := arithType(v.typ, v.typ)
+		v = in.convert(v, t, expr)
+
+		switch {
+		case expr.Op.SecondaryType == Add:
+			return v
+		case t.kind == floatKind && expr.Op.SecondaryType == Sub:
+			return floatValue(t, -v.f)
+		case t.kind == floatKind:
+			in.error("Invalid operand "+t.String()+" for '~'.", expr)
+		case expr.Op.SecondaryType == Sub:
+			return intValue(t, -v.i)
+		}
+		return intValue(t, ^v.i)
+	}
+	in.error("Unknown operator '"+string(expr.Op.Buff)+"'.", expr)
+	return void
+}
+
+// incr adds delta to a variable, pointers move by the size of their element, prefix returns the new value and postfix the old one
+func (in *Interpreter) incr(expr Expression, delta int64, prefix bool, sc *scope) value {
+	addr, t, ok := in.lvalue(expr, sc)
+	if !ok {
+		in.error("Expression is not assignable.", expr)
+	}
+
+	old := in.load(addr, t, expr)
+	v := old
+
+	switch t.kind {
+	case intKind:
+		v = intValue(t, old.i+uint64(delta))
+	case floatKind:
+		v = floatValue(t, old.f+float64(delta))
+	case pointerKind:
+		v.i += uint64(delta * int64(t.elem.size))
+	default:
+		in.error("Cannot increment a value of type "+t.String()+".", expr)
+	}
+	in.store(addr, v, expr)
+
+	if prefix {
+		return v
+	}
+	return old
+}
+
+// snapshot copies the locals a closure can see, C blocks capture variables by value unless they are declared capture or static
+func (in *Interpreter) snapshot(sc *scope) *scope {
+	if !sc.local {
+		return sc
+	}
+	snap := &scope{names: map[string]interface{}{}, module: sc.module, local: true}
+
+	for ; sc.local; sc = sc.parent {
+		for name, b := range sc.names {
+			if _, ok := snap.names[name]; ok {
+				continue
+			}
+			if v, ok := b.(*variable); ok && !v.captured {
+				addr := in.alloc(v.typ, NullStatement{})
+				copy(in.mem.bytes(addr, v.typ.size), in.mem.bytes(v.addr, v.typ.size))
+				b = &variable{addr: addr, typ: v.typ}
+			}
+			snap.names[name] = b
+		}
+	}
+	snap.parent = sc
+	return snap
+}
+
+// compound evaluates a compound literal, fields left out of struct literals take their default values
+func (in *Interpreter) compound(expr CompoundLiteral, sc *scope) value {
+	t := in.resolve(expr.Name, sc)
+	data := expr.Data
+
+	values := make([]value, len(data.Values))
+	for x, val := range data.Values {
+		values[x] = in.eval(val, sc)
+	}
+
+	switch t.kind {
+	case vecKind:
+		for x := range values {
+			values[x] = in.convert(values[x], t.elem, data.Values[x])
+		}
+		return in.newVector(t, values)
+	case promiseKind:
+		v, _ := in.newPromise(t)
+		return v
+	case arrayKind:
+		return in.convert(value{typ: listType, list: values}, t, expr)
+	case structKind, tupleKind, unionKind:
+		v := in.defaults(t, expr)
+		if len(data.Fields) == 0 {
+			if len(values) > len(t.fields) {
+				in.error("Too many values for "+t.String()+".", expr)
+			}
+			for x, val := range values {
+				f := t.fields[x]
+				in.convert(val, f.typ, data.Values[x]).encode(v.b[f.offset : f.offset+f.typ.size])
+			}
+			return v
+		}
+		for x, name := range data.Fields {
+			f, ok := t.field(string(name.Buff))
+			if !ok {
+				in.error(t.String()+" has no member called '"+string(name.Buff)+"'.", data.Values[x])
+			}
+			in.convert(values[x], f.typ, data.Values[x]).encode(v.b[f.offset : f.offset+f.typ.size])
+		}
+		return v
+	}
+
+	if len(values) != 1 {
+		in.error("Expected one value for "+t.String()+".", expr)
+	}
+	return in.convert(values[0], t, expr)
+}
+
+// defaults returns a value of t with the default values of its fields, they are evaluated once like in C
+func (in *Interpreter) defaults(t *rtype, node positioned) value {
+	if t.defaults == nil {
+		t.defaults = make([]byte, t.size)
+
+		for _, f := range t.fields {
+			if f.value == nil {
+				continue
+			}
+			in.convert(in.eval(f.value, t.env), f.typ, f.value).encode(t.defaults[f.offset : f.offset+f.typ.size])
+		}
+	}
+	return value{typ: t, b: append([]byte{}, t.defaults...)}
+}
+
+// heapAlloc evaluates new T, new T(value) and new T{...}, the memory is zeroed unless it is initialized
+func (in *Interpreter) heapAlloc(expr HeapAlloc, sc *scope) value {
+	t := in.resolve(expr.Type, sc)
+	addr := in.alloc(t, expr)
+
+	if expr.Val != nil {
+		in.store(addr, in.convert(in.eval(expr.Val, sc), t, expr), expr)
+	}
+	return value{typ: pointerTo(t), i: addr}
+}
+
+// declType is the type a variable declared without one takes, it follows the analyzer so that programs behave like their C
+func (in *Interpreter) declType(v value, expr Expression, sc *scope) *rtype {
+	t, ok := in.typeOf(expr, sc)
+
+	switch {
+	case !ok:
+		switch expr.(type) {
+		case BasicLit:
+			if strings.Contains(string(expr.(BasicLit).Value.Buff), ".") {
+				return f32Type
+			}
+		}
+		return i32Type
+	case t != nil:
+		return t
+	case v.typ.kind == listKind:
+		in.error("Cannot infer the type of an array literal.", expr)
+	}
+	return v.typ
+}
+
+// typeOf returns the static type of the expressions whose type in C differs from the type of their value,
+// nil if it is the type of the value and ok is false for numbers, which take the type they are declared with
+func (in *Interpreter) typeOf(expr Expression, sc *scope) (*rtype, bool) {
+	switch expr.(type) {
+	case BasicLit:
+		return nil, expr.(BasicLit).Value.PrimaryType == StringLiteral
+	case IdentExpr:
+		if v, ok := sc.lookup(string(expr.(IdentExpr).Value.Buff)).(*variable); ok {
+			return v.typ, true
+		}
+	case BinaryExpr:
+		if expr.(BinaryExpr).Op.PrimaryType == RelationalOperator {
+			return boolType, true
+		}
+		if t, ok := in.typeOf(expr.(BinaryExpr).Left, sc); ok {
+			return t, true
+		}
+		return in.typeOf(expr.(BinaryExpr).Right, sc)
+	case TernaryExpr:
+		if t, ok := in.typeOf(expr.(TernaryExpr).Left, sc); ok {
+			return t, true
+		}
+		return in.typeOf(expr.(TernaryExpr).Right, sc)
+	case UnaryExpr:
+		switch expr.(UnaryExpr).Op.SecondaryType {
+		case Mul, And:
+			return nil, true
+		}
+		return in.typeOf(expr.(UnaryExpr).Expr, sc)
+	case PostfixUnaryExpr:
+		return in.typeOf(expr.(PostfixUnaryExpr).Expr, sc)
+	}
+	return nil, true
+}
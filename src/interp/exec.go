@@ -0,0 +1,295 @@
+package interp
+
+import (
+	. "parser"
+	"printer"
+)
+
+// control tells the statements around a statement how it finished
+type control byte
+
+const (
+	next control = iota
+	brk
+	cont
+	ret
+)
+
+// block runs a block in a new scope, its defers run and its stack is freed when it exits
+func (in *Interpreter) block(block Block, sc *scope) control {
+	sc = sc.child()
+	sp := in.mem.sp()
+
+	ctl := in.stmts(block.Statements, sc)
+
+	for x := len(sc.defers) - 1; x >= 0; x-- {
+		in.exec(sc.defers[x], sc)
+	}
+	in.mem.pop(sp)
+	return ctl
+}
+
+func (in *Interpreter) stmts(stmts []Statement, sc *scope) control {
+	for _, stmt := range stmts {
+		if ctl := in.exec(stmt, sc); ctl != next {
+			return ctl
+		}
+	}
+	return next
+}
+
+func (in *Interpreter) exec(stmt Statement, sc *scope) control {
+	switch stmt.(type) {
+	case Declaration:
+		in.declaration(stmt.(Declaration), sc)
+	case Typedef:
+		in.typedef(stmt.(Typedef), sc)
+	case Import:
+		in.imprt(stmt.(Import), sc)
+	case ExportStatement:
+		return in.exec(stmt.(ExportStatement).Stmt, sc)
+	case Block:
+		return in.block(stmt.(Block), sc)
+	case IfElseBlock:
+		return in.ifElse(stmt.(IfElseBlock), sc)
+	case Loop:
+		return in.loop(stmt.(Loop), sc)
+	case Switch:
+		return in.swtch(stmt.(Switch), sc)
+	case Assignment:
+		in.assignment(stmt.(Assignment), sc)
+	case Return:
+		if in.frame == nil {
+			in.error("Return outside of a function.", stmt)
+		}
+		if vals := stmt.(Return).Values; len(vals) > 0 {
+			in.frame.ret = in.eval(vals[0], sc)
+		}
+		return ret
+	case Break:
+		return brk
+	case Continue:
+		return cont
+	case Defer:
+		sc.defers = append(sc.defers, stmt.(Defer).Stmt)
+	case Delete:
+		// the heap is never reused, delete only evaluates its operands
+		for _, expr := range stmt.(Delete).Exprs {
+			in.eval(expr, sc)
+		}
+	case Assert:
+		if cond := stmt.(Assert).Cond; !in.truthy(in.eval(cond, sc), cond) {
+			in.error("Assertion failed: "+string(printer.PrintExpression(cond))+".", stmt)
+		}
+	case Test, NullStatement:
+		break
+	case Expression:
+		in.eval(stmt.(Expression), sc)
+	default:
+		in.error("Unsupported statement.", stmt)
+	}
+	return next
+}
+
+func (in *Interpreter) ifElse(ifElse IfElseBlock, sc *scope) control {
+	if ifElse.HasInitStmt {
+		sc = sc.child()
+		defer in.mem.pop(in.mem.sp())
+		in.exec(ifElse.InitStatement, sc)
+	}
+
+	for x, cond := range ifElse.Conditions {
+		if in.truthy(in.eval(cond, sc), cond) {
+			return in.block(ifElse.Blocks[x], sc)
+		}
+	}
+	return in.block(ifElse.ElseBlock, sc)
+}
+
+func (in *Interpreter) loop(loop Loop, sc *scope) control {
+	sc = sc.child()
+	defer in.mem.pop(in.mem.sp())
+
+	if loop.Type&InitLoop != 0 {
+		in.exec(loop.InitStatement, sc)
+	}
+
+	for {
+		if loop.Type&CondLoop != 0 && !in.truthy(in.eval(loop.Condition, sc), loop.Condition) {
+			return next
+		}
+
+		switch in.block(loop.Block, sc) {
+		case brk:
+			return next
+		case ret:
+			return ret
+		}
+
+		if loop.Type&LoopLoop != 0 {
+			in.exec(loop.LoopStatement, sc)
+		}
+	}
+}
+
+// swtch runs the matching case and the cases after it until a break, like a C switch, the default case is last
+func (in *Interpreter) swtch(swtch Switch, sc *scope) control {
+	sc = sc.child()
+	defer in.mem.pop(in.mem.sp())
+
+	if swtch.Type == InitCondSwitch {
+		in.exec(swtch.InitStatement, sc)
+	}
+
+	var val value
+	if swtch.Type != NoneSwtch {
+		val = in.eval(swtch.Expr, sc)
+	}
+
+	matched := len(swtch.Cases)
+	for x, c := range swtch.Cases {
+		v := in.eval(c.Condition, sc)
+		if swtch.Type != NoneSwtch {
+			v = in.binary(Token{SecondaryType: EqualEqual, Buff: []byte("==")}, val, v, c.Condition)
+		}
+		if in.truthy(v, c.Condition) {
+			matched = x
+			break
+		}
+	}
+	if matched == len(swtch.Cases) && !swtch.HasDefaultCase {
+		return next
+	}
+
+	for _, c := range swtch.Cases[matched:] {
+		switch ctl := in.block(c.Block, sc); ctl {
+		case brk:
+			return next
+		case next:
+			continue
+		default:
+			return ctl
+		}
+	}
+
+	if swtch.HasDefaultCase {
+		if ctl := in.block(swtch.DefaultCase, sc); ctl != brk {
+			return ctl
+		}
+	}
+	return next
+}
+
+// ops are the operators of compound assignments
+var ops = map[SecondaryTokenType]Token{
+	AddEqual:     {SecondaryType: Add, Buff: []byte("+")},
+	SubEqual:     {SecondaryType: Sub, Buff: []byte("-")},
+	MulEqual:     {SecondaryType: Mul, Buff: []byte("*")},
+	DivEqual:     {SecondaryType: Div, Buff: []byte("/")},
+	ModulusEqual: {SecondaryType: Modulus, Buff: []byte("%")},
+}
+
+// assignment assigns the variables one after the other, a single value is assigned to all of them
+func (in *Interpreter) assignment(as Assignment, sc *scope) {
+	for x, variable := range as.Variables {
+		expr := as.Values[0]
+		if x < len(as.Values) {
+			expr = as.Values[x]
+		}
+
+		addr, t, ok := in.lvalue(variable, sc)
+		if !ok {
+			in.error("Expression is not assignable.", variable)
+		}
+		v := in.eval(expr, sc)
+
+		if op, ok := ops[as.Op.SecondaryType]; ok {
+			v = in.binary(op, in.decay(in.load(addr, t, variable), variable), in.decay(v, expr), as)
+		}
+		in.store(addr, in.convert(v, t, expr), variable)
+	}
+}
+
+// declaration allocates variables on the stack in functions and on the heap everywhere else,
+// static variables are allocated once and capture variables outlive the function that declared them
+func (in *Interpreter) declaration(dec Declaration, sc *scope) {
+	for x, ident := range dec.Identifiers {
+		var typ Type
+		if len(dec.Types) == 1 {
+			typ = dec.Types[0]
+		} else if x < len(dec.Types) {
+			typ = dec.Types[x]
+		}
+
+		static, captured := false, false
+		switch typ.(type) {
+		case StaticType:
+			static = true
+		case CaptureType:
+			captured = true
+		}
+
+		name := string(ident.Buff)
+		if v, ok := in.statics[&ident.Buff[0]]; ok && static {
+			sc.names[name] = v
+			continue
+		}
+
+		var t *rtype
+		var v value
+		if x < len(dec.Values) {
+			v = in.eval(dec.Values[x], sc)
+		}
+
+		switch {
+		case typ != nil:
+			t = in.resolve(typ, sc)
+		case x < len(dec.Values):
+			t = in.declType(v, dec.Values[x], sc)
+		default:
+			in.error("Cannot declare variable without type.", dec)
+		}
+
+		if x < len(dec.Values) {
+			v = in.convert(v, t, dec.Values[x])
+			t = v.typ
+		} else if t.kind == arrayKind && t.length < 0 {
+			in.error("Cannot declare an array of implicit length without a value.", dec)
+		}
+
+		b := &variable{typ: t, captured: static || captured}
+		if static || captured || !sc.local {
+			b.addr = in.alloc(t, dec)
+		} else {
+			b.addr = in.push(t, dec)
+		}
+
+		if x < len(dec.Values) {
+			in.store(b.addr, v, dec)
+		}
+		if static {
+			in.statics[&ident.Buff[0]] = b
+		}
+		sc.names[name] = b
+	}
+}
+
+// typedef binds the name before resolving structs, so that their fields can point to them
+func (in *Interpreter) typedef(typedef Typedef, sc *scope) {
+	name := string(typedef.Name.Buff)
+
+	switch typedef.Type.(type) {
+	case StructType:
+		t := &rtype{kind: structKind, name: name}
+		sc.names[name] = &typeName{t}
+		in.strct(t, typedef.Type.(StructType), sc)
+		return
+	}
+
+	t := in.resolve(typedef.Type, sc)
+	switch typedef.Type.(type) {
+	case TupleType, UnionType, EnumType:
+		t.name = name
+	}
+	sc.names[name] = &typeName{t}
+}
@@ -0,0 +1,89 @@
+package interp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// format prints v the way the REPL shows values, strings are quoted and aggregates are printed field by field
+func (in *Interpreter) format(v value) string {
+	t := v.typ
+
+	switch t.kind {
+	case intKind:
+		for name, n := range t.values {
+			if uint64(n) == v.i {
+				return t.name + "." + name
+			}
+		}
+		switch {
+		case t == boolType && v.i <= 1:
+			return strconv.FormatBool(v.i == 1)
+		case t.signed:
+			return strconv.FormatInt(int64(v.i), 10)
+		}
+		return strconv.FormatUint(v.i, 10)
+	case floatKind:
+		return strconv.FormatFloat(v.f, 'g', -1, t.size*8)
+	case pointerKind:
+		if v.i == 0 {
+			return "null"
+		}
+		if t.elem.size == 1 && t.elem.kind == intKind {
+			if str, ok := in.mem.cString(v.i); ok {
+				return strconv.Quote(string(str))
+			}
+		}
+		return "0x" + strconv.FormatUint(v.i, 16)
+	case arrayKind:
+		// arrays of bytes are usually strings
+		if t.elem.size == 1 && t.elem.kind == intKind && t.length > 0 && v.b[t.length-1] == 0 {
+			return strconv.Quote(strings.SplitN(string(v.b), "\x00", 2)[0])
+		}
+		elems := []string{}
+		for x := 0; x < t.length; x++ {
+			elems = append(elems, in.format(decode(t.elem, v.b[x*t.elem.size:(x+1)*t.elem.size])))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case listKind:
+		elems := []string{}
+		for _, el := range v.list {
+			elems = append(elems, in.format(el))
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
+	case structKind, unionKind, tupleKind:
+		fields := []string{}
+		for _, f := range t.fields {
+			val := in.format(decode(f.typ, v.b[f.offset:f.offset+f.typ.size]))
+			if t.kind != tupleKind {
+				val = f.name + ": " + val
+			}
+			fields = append(fields, val)
+		}
+		return t.String() + "{" + strings.Join(fields, ", ") + "}"
+	case vecKind:
+		vec, ok := in.object(v.i).(*vector)
+		if !ok {
+			return "(" + t.String() + ")null"
+		}
+		elems := []string{}
+		for x := 0; x < vec.length; x++ {
+			elems = append(elems, in.format(decode(t.elem, in.mem.bytes(vec.addr+uint64(x*t.elem.size), t.elem.size))))
+		}
+		return "(" + t.String() + "){" + strings.Join(elems, ", ") + "}"
+	case promiseKind:
+		prom, ok := in.object(v.i).(*promise)
+		switch {
+		case !ok:
+			return "(" + t.String() + ")null"
+		case prom.state == promiseResolved:
+			return "(" + t.String() + ") resolved " + in.format(prom.val)
+		case prom.state == promiseRejected:
+			return "(" + t.String() + ") rejected " + in.format(prom.reason)
+		}
+		return "(" + t.String() + ") pending"
+	case funcKind:
+		return t.String()
+	}
+	return ""
+}
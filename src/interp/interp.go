@@ -0,0 +1,385 @@
+// Package interp runs Volant programs by walking their AST, without generating C
+package interp
+
+import (
+	"bufio"
+	"compiler"
+	"error"
+	"io"
+	"io/ioutil"
+	. "parser"
+	"path"
+	Path "path/filepath"
+	"strconv"
+	"strings"
+)
+
+// handles of closures, vectors and promises start at objectBase, far above the heap and the stack
+const objectBase = 1 << 56
+
+const maxDepth = 10000
+
+// Interpreter holds the memory and the loaded modules of a program
+type Interpreter struct {
+	Stdin  *bufio.Reader
+	Stdout *bufio.Writer
+
+	mem     memory
+	objects []interface{}
+	modules map[string]*module
+	strings map[*byte]uint64    // string literals, they are stored once like in C
+	statics map[*byte]*variable // static variables by the identifier they are declared with
+	frame   *frame
+	path    string // the file being run, for errors
+	depth   int
+}
+
+// frame is a running function call
+type frame struct {
+	ret value
+}
+
+// exit unwinds the interpreter when the program calls $exit
+type exit int
+
+// scope maps names to variables, types, constants and modules
+type scope struct {
+	parent *scope
+	names  map[string]interface{}
+	module *module
+	local  bool // scopes in functions, closures copy the variables in them
+	defers []Statement
+}
+
+type variable struct {
+	addr     uint64
+	typ      *rtype
+	captured bool // capture and static variables are shared with closures
+}
+
+type typeName struct {
+	typ *rtype
+}
+
+type constant struct {
+	val value
+}
+
+type module struct {
+	path  string
+	scope *scope
+}
+
+type closure struct {
+	fnc  FuncExpr
+	env  *scope
+	path string
+	self *rtype // the struct an inherited method was inherited by
+	typ  *rtype
+}
+
+var universe = &scope{names: map[string]interface{}{
+	"true":  &constant{value{typ: boolType, i: 1}},
+	"false": &constant{value{typ: boolType}},
+	"null":  &constant{value{typ: voidPointer}},
+}}
+
+func init() {
+	for _, t := range builtinTypes {
+		universe.names[t.name] = &typeName{t}
+	}
+}
+
+// New returns an interpreter that reads and writes the standard streams of programs from stdin and to stdout
+func New(stdin io.Reader, stdout io.Writer) *Interpreter {
+	return &Interpreter{
+		Stdin:   bufio.NewReader(stdin),
+		Stdout:  bufio.NewWriter(stdout),
+		modules: map[string]*module{},
+		strings: map[*byte]uint64{},
+		statics: map[*byte]*variable{},
+	}
+}
+
+func (sc *scope) child() *scope {
+	return &scope{parent: sc, names: map[string]interface{}{}, module: sc.module, local: sc.local}
+}
+
+func (sc *scope) lookup(name string) interface{} {
+	for ; sc != nil; sc = sc.parent {
+		if b, ok := sc.names[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (sc *scope) path() string {
+	if sc.module == nil {
+		return ""
+	}
+	return sc.module.path
+}
+
+// positioned is any node of the AST
+type positioned interface {
+	LineM() int
+	ColumnM() int
+}
+
+func (in *Interpreter) error(message string, node positioned) {
+	error.NewFileError(in.path, message, node.LineM(), node.ColumnM())
+}
+
+// RunFile parses, analyzes and runs the file at path, and returns the exit code of the program
+func RunFile(in *Interpreter, file string) int {
+	code, err := ioutil.ReadFile(file)
+	if err != nil {
+		error.NewGenError("error reading file: " + err.Error())
+	}
+
+	compiler.NoEmit = true
+	ast := ParseFile(&Lexer{Buffer: code, Line: 1, Column: 1, Path: file})
+	compiler.AnalyzeFile(ast, file)
+
+	return in.Run(ast, file)
+}
+
+// Run runs the main function of ast, which has been analyzed, and returns the exit code of the program
+func (in *Interpreter) Run(ast File, file string) (code int) {
+	defer in.Stdout.Flush()
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(exit)
+			if !ok {
+				panic(r)
+			}
+			code = int(e)
+		}
+	}()
+
+	m := in.loadModule(ast, file)
+	main, ok := m.scope.names["main"].(*variable)
+
+	if !ok || main.typ.kind != funcKind {
+		error.NewFileError(file, "No main function.", 0, 0)
+	}
+
+	in.path = file
+	ret := in.call(in.closure(in.load(main.addr, main.typ, NullStatement{}), NullStatement{}), nil, NullStatement{})
+
+	if ret.typ.kind == intKind {
+		return int(int32(ret.i))
+	}
+	return 0
+}
+
+// loadModule runs the global statements of a module
+func (in *Interpreter) loadModule(ast File, file string) *module {
+	m := &module{path: file}
+	m.scope = &scope{parent: universe, names: map[string]interface{}{}, module: m}
+	in.modules[file] = m
+
+	prev := in.path
+	in.path = file
+
+	for _, stmt := range ast.Statements {
+		in.global(stmt, m.scope)
+	}
+	in.path = prev
+	return m
+}
+
+func (in *Interpreter) global(stmt Statement, sc *scope) {
+	switch stmt.(type) {
+	case Import:
+		in.imprt(stmt.(Import), sc)
+	case Declaration:
+		in.declaration(stmt.(Declaration), sc)
+	case Typedef:
+		in.typedef(stmt.(Typedef), sc)
+	case ExportStatement:
+		in.global(stmt.(ExportStatement).Stmt, sc)
+	case Test, NullStatement:
+		break
+	default:
+		in.error("Non-declarative statement outside function body.", stmt)
+	}
+}
+
+// imprt binds imported modules by the base name of their file, headers are C and are skipped
+func (in *Interpreter) imprt(stmt Import, sc *scope) {
+	for _, tok := range stmt.Paths {
+		file := path.Clean(string(tok.Buff[1 : len(tok.Buff)-1]))
+		if path.Ext(file) == ".h" {
+			continue
+		}
+		sc.names[strings.Split(path.Base(file), ".")[0]] = in.module(path.Dir(in.path), file, tok)
+	}
+}
+
+func (in *Interpreter) module(dir string, base string, tok Token) *module {
+	tried := []string{}
+
+	for _, d := range compiler.SearchPath(dir) {
+		file := Path.Join(d, base)
+		if m, ok := in.modules[file]; ok {
+			return m
+		}
+
+		code, err := ioutil.ReadFile(file)
+		if err != nil {
+			tried = append(tried, file)
+			continue
+		}
+		return in.loadModule(ParseFile(&Lexer{Buffer: code, Line: 1, Column: 1, Path: file}), file)
+	}
+
+	error.NewFileError(in.path, "error finding import \""+base+"\", tried:\n\t"+strings.Join(tried, "\n\t"), tok.Line, tok.Column)
+	return nil
+}
+
+// moduleOf returns the module expr names, nil if it is not the name of an imported module
+func (in *Interpreter) moduleOf(expr Expression, sc *scope) *module {
+	switch expr.(type) {
+	case IdentExpr:
+		m, _ := sc.lookup(string(expr.(IdentExpr).Value.Buff)).(*module)
+		return m
+	}
+	return nil
+}
+
+func (in *Interpreter) handle(obj interface{}) uint64 {
+	in.objects = append(in.objects, obj)
+	return objectBase + uint64(len(in.objects)-1)
+}
+
+func (in *Interpreter) object(h uint64) interface{} {
+	if h < objectBase || h-objectBase >= uint64(len(in.objects)) {
+		return nil
+	}
+	return in.objects[h-objectBase]
+}
+
+func (in *Interpreter) closure(v value, node positioned) *closure {
+	c, ok := in.object(v.i).(*closure)
+	if !ok {
+		in.error("Call of an invalid function pointer 0x"+strconv.FormatUint(v.i, 16)+".", node)
+	}
+	return c
+}
+
+// alloc returns size zeroed bytes on the heap
+func (in *Interpreter) alloc(t *rtype, node positioned) uint64 {
+	addr := in.mem.alloc(t.size, t.align)
+	if addr == 0 {
+		in.error("Out of memory.", node)
+	}
+	return addr
+}
+
+// push returns size zeroed bytes on the stack, they are freed when the block they were pushed in exits
+func (in *Interpreter) push(t *rtype, node positioned) uint64 {
+	addr := in.mem.push(t.size, t.align)
+	if addr == 0 {
+		in.error("Stack overflow.", node)
+	}
+	return addr
+}
+
+// temp stores an aggregate that is not in memory on the stack, so that it has an address
+func (in *Interpreter) temp(v value, node positioned) uint64 {
+	if v.typ.kind == arrayKind && v.i != 0 {
+		return v.i
+	}
+	if v.typ.kind == listKind {
+		in.error("Array literals have no address, give them a type with a compound literal.", node)
+	}
+	addr := in.push(v.typ, node)
+	in.store(addr, v, node)
+	return addr
+}
+
+func (in *Interpreter) load(addr uint64, t *rtype, node positioned) value {
+	b := in.mem.bytes(addr, t.size)
+	if b == nil {
+		in.error("Invalid memory access at 0x"+strconv.FormatUint(addr, 16)+".", node)
+	}
+	v := decode(t, b)
+	if t.kind == arrayKind {
+		v.i = addr
+	}
+	return v
+}
+
+func (in *Interpreter) store(addr uint64, v value, node positioned) {
+	b := in.mem.bytes(addr, v.typ.size)
+	if b == nil {
+		in.error("Invalid memory access at 0x"+strconv.FormatUint(addr, 16)+".", node)
+	}
+	v.encode(b)
+}
+
+// closureType resolves the type of c, inherited methods take the struct that inherited them as self
+func (in *Interpreter) closureType(c *closure) *rtype {
+	if c.typ != nil {
+		return c.typ
+	}
+	t := in.funcType(c.fnc.Type, c.env)
+
+	if c.self != nil && len(t.args) > 0 {
+		self := *t
+		self.args = append([]*rtype{}, t.args...)
+
+		switch a := t.args[0]; {
+		case a.kind == structKind:
+			self.args[0] = c.self
+		case a.kind == pointerKind && a.elem.kind == structKind:
+			self.args[0] = pointerTo(c.self)
+		}
+		t = &self
+	}
+	c.typ = t
+	return t
+}
+
+// call binds args to the parameters of c on the stack and runs its body
+func (in *Interpreter) call(c *closure, args []value, node positioned) value {
+	t := in.closureType(c)
+
+	if len(args) != len(t.args) {
+		in.error("Expected "+strconv.Itoa(len(t.args))+" arguments, got "+strconv.Itoa(len(args))+".", node)
+	}
+	if in.depth >= maxDepth {
+		in.error("Stack overflow.", node)
+	}
+
+	sp := in.mem.sp()
+	sc := c.env.child()
+	sc.local = true
+
+	for x, arg := range t.args {
+		addr := in.push(arg, node)
+		in.store(addr, in.convert(args[x], arg, node), node)
+		sc.names[string(c.fnc.Type.ArgNames[x].Buff)] = &variable{addr: addr, typ: arg}
+	}
+
+	fr, file := in.frame, in.path
+	in.frame, in.path = &frame{}, c.path
+	in.depth++
+
+	in.block(c.fnc.Block, sc)
+	ret := in.frame.ret
+
+	in.depth--
+	in.frame, in.path = fr, file
+	in.mem.pop(sp)
+
+	switch {
+	case t.ret.kind == voidKind:
+		return void
+	case ret.typ == nil:
+		return decode(t.ret, make([]byte, t.ret.size))
+	}
+	return in.convert(ret, t.ret, node)
+}
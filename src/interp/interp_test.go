@@ -0,0 +1,94 @@
+package interp_test
+
+import (
+	"bytes"
+	"compiler"
+	"error"
+	"internal/golden"
+	"interp"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// examples whose output does not depend on addresses
+var examples = []string{"arrays", "closures", "heapmemory", "helloworld", "structs", "testing_test", "tuples", "unions", "vectors"}
+
+func TestRun(t *testing.T) {
+	compiler.ImportPaths = []string{"../../lib"}
+	defer func() {
+		compiler.ImportPaths = nil
+	}()
+
+	files, _ := filepath.Glob("testdata/*.vo")
+	for _, name := range examples {
+		files = append(files, filepath.Join("../../examples", name+".vo"))
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			code := 0
+
+			if err := error.Catch(func() {
+				code = interp.RunFile(interp.New(strings.NewReader(""), out), file)
+			}); err != nil {
+				t.Fatal(err)
+			}
+			out.WriteString("exit " + strconv.Itoa(code) + "\n")
+
+			golden.Check(t, filepath.Join("testdata", "golden", strings.TrimSuffix(filepath.Base(file), ".vo")+".out"), out.Bytes())
+		})
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := map[string]string{
+		"func main() i32 { a: [2]i32; i := 2; return a[i]; }":                       "Index 2 out of range for [2]i32.",
+		"func main() i32 { a: i32 = 0; return 1 / a; }":                             "Division by zero.",
+		"func main() i32 { p := cast(*i32)null; return *p; }":                       "Invalid memory access at 0x0.",
+		"func main() i32 { v := (vec i32){}; v.pop(); return 0; }":                  "Pop from an empty vector.",
+		"func f(n: i32) i32 { return f(n + 1); }\nfunc main() i32 { return f(0); }": "Stack overflow.",
+		"func main() i32 { s: str = \"abc\"; i := 3; return cast(i32)s[i]; }":       "Index 3 out of range for a str of length 3.",
+		"func main() i32 { s: str = \"abc\"; t := s.slice(2, 4); return 0; }":       "Slice [2:4] out of range for a str of length 3.",
+	}
+
+	for src, want := range tests {
+		file := filepath.Join(t.TempDir(), "main.vo")
+		if err := ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := error.Catch(func() {
+			interp.RunFile(interp.New(strings.NewReader(""), ioutil.Discard), file)
+		})
+		if err == nil || err.Message != want {
+			t.Errorf("%s: got error %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestRepl(t *testing.T) {
+	input := strings.Join([]string{
+		"x := 20",
+		"x * 2 + 2",
+		"func sq(n: i32) i32 {",
+		"    return n * n;",
+		"}",
+		"sq(x)",
+		"y",
+		"s := \"hi\"",
+		"s",
+		"x > 3",
+		"for i := 0; i < 3; ++i { $printf(\"%d \", i); }",
+		":quit",
+		"x",
+	}, "\n")
+
+	out := &bytes.Buffer{}
+	interp.Repl(strings.NewReader(input), out)
+
+	golden.Check(t, "testdata/golden/repl.out", out.Bytes())
+}
@@ -0,0 +1,85 @@
+package interp
+
+// the address space is split in two, addresses below heapBase are never valid so that null and small handles fault
+const (
+	heapBase  = 0x1000
+	stackBase = 0x100000000
+	maxStack  = 64 << 20
+	maxHeap   = 1 << 30
+)
+
+// memory is the simulated address space of a program, the heap is never reused and the stack is popped when blocks exit
+type memory struct {
+	heap  []byte
+	stack []byte
+	sizes map[uint64]int // sizes of the blocks returned by malloc, for realloc
+}
+
+func align(n int, a int) int {
+	if a <= 1 {
+		return n
+	}
+	return (n + a - 1) / a * a
+}
+
+// alloc returns the address of size zeroed bytes on the heap, 0 if the heap is full
+func (m *memory) alloc(size int, a int) uint64 {
+	start := align(len(m.heap), a)
+	if start+size > maxHeap {
+		return 0
+	}
+	m.heap = append(m.heap, make([]byte, start+size-len(m.heap))...)
+	return heapBase + uint64(start)
+}
+
+// push returns the address of size zeroed bytes on the stack, 0 if the stack overflowed
+func (m *memory) push(size int, a int) uint64 {
+	start := align(len(m.stack), a)
+	if start+size > maxStack {
+		return 0
+	}
+	m.stack = append(m.stack, make([]byte, start+size-len(m.stack))...)
+	return stackBase + uint64(start)
+}
+
+func (m *memory) sp() int {
+	return len(m.stack)
+}
+
+// pop frees everything pushed after sp
+func (m *memory) pop(sp int) {
+	m.stack = m.stack[:sp]
+}
+
+// bytes returns the size bytes at addr, nil if any of them is outside of the heap and the stack
+func (m *memory) bytes(addr uint64, size int) []byte {
+	switch {
+	case addr >= stackBase:
+		off := addr - stackBase
+		if off+uint64(size) <= uint64(len(m.stack)) && off+uint64(size) >= off {
+			return m.stack[off : off+uint64(size)]
+		}
+	case addr >= heapBase:
+		off := addr - heapBase
+		if off+uint64(size) <= uint64(len(m.heap)) {
+			return m.heap[off : off+uint64(size)]
+		}
+	}
+	return nil
+}
+
+// cString returns the bytes at addr up to the first 0
+func (m *memory) cString(addr uint64) ([]byte, bool) {
+	str := []byte{}
+	for {
+		b := m.bytes(addr, 1)
+		if b == nil {
+			return str, false
+		}
+		if b[0] == 0 {
+			return str, true
+		}
+		str = append(str, b[0])
+		addr++
+	}
+}
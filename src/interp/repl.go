@@ -0,0 +1,193 @@
+package interp
+
+import (
+	"compiler"
+	"error"
+	"io"
+	. "parser"
+	"strings"
+)
+
+const replPath = "<repl>"
+
+// Session evaluates input one piece at a time, declarations stay in the module of the session
+type Session struct {
+	in    *Interpreter
+	mod   *module
+	decls []Statement // the global statements accepted so far, new input is analyzed with them
+}
+
+func NewSession(in *Interpreter) *Session {
+	m := &module{path: replPath}
+	m.scope = &scope{parent: universe, names: map[string]interface{}{}, module: m}
+	return &Session{in: in, mod: m}
+}
+
+// Eval runs src and returns the values of its expression statements, one per line
+// src is either global declarations, which are kept, or statements, which run as the body of a function
+func (s *Session) Eval(src string) string {
+	s.in.path = replPath
+	defer s.reset()
+
+	var globals File
+	globalErr := error.Catch(func() {
+		globals = ParseFile(&Lexer{Buffer: []byte(src), Line: 1, Column: 1, Path: replPath})
+	})
+
+	if globalErr == nil {
+		s.analyze(globals.Statements)
+		for _, stmt := range globals.Statements {
+			s.in.global(stmt, s.mod.scope)
+		}
+		s.decls = append(s.decls, globals.Statements...)
+		return ""
+	}
+
+	// the function starts on line 0 so that positions in errors are the ones in src
+	var body File
+	bodyErr := error.Catch(func() {
+		body = ParseFile(&Lexer{Buffer: []byte("func __repl() {\n" + src + "\n}"), Line: 0, Column: 1, Path: replPath})
+	})
+
+	// the parse that got further is more likely to be what was meant
+	if bodyErr != nil {
+		if globalErr.Line > bodyErr.Line || globalErr.Line == bodyErr.Line && globalErr.Column > bodyErr.Column {
+			error.Handler(globalErr)
+		}
+		error.Handler(bodyErr)
+	}
+	s.analyze(body.Statements)
+
+	stmts := body.Statements[0].(Declaration).Values[0].(FuncExpr).Block.Statements
+	sc := s.mod.scope.child()
+	sc.local = true
+	s.in.frame = &frame{}
+	out := []string{}
+
+	for _, stmt := range stmts {
+		switch stmt.(type) {
+		case Expression:
+			v := s.in.eval(stmt.(Expression), sc)
+			if v.typ.kind != voidKind && !isCCall(stmt.(Expression)) {
+				out = append(out, s.in.format(v))
+			}
+			continue
+		}
+		if s.in.exec(stmt, sc) == ret {
+			break
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// analyze checks stmts with the declarations before them, like the analyzer checks a file
+func (s *Session) analyze(stmts []Statement) {
+	compiler.NoEmit = true
+	all := append(append([]Statement{}, s.decls...), stmts...)
+	compiler.AnalyzeFile(File{Path: replPath, Statements: all}, replPath)
+}
+
+// reset frees the stack and forgets the calls an error left running
+func (s *Session) reset() {
+	s.in.Stdout.Flush()
+	s.in.mem.pop(0)
+	s.in.frame, s.in.depth = nil, 0
+}
+
+// the values of C functions like $printf are not printed
+func isCCall(expr Expression) bool {
+	switch expr.(type) {
+	case CallExpr:
+		switch fn := expr.(CallExpr).Function; fn.(type) {
+		case IdentExpr:
+			return strings.HasPrefix(string(fn.(IdentExpr).Value.Buff), "$")
+		}
+	}
+	return false
+}
+
+// depth counts the brackets src leaves open, input is read until they are closed
+func depth(src string) int {
+	n := 0
+	var quote byte
+
+	for x := 0; x < len(src); x++ {
+		c := src[x]
+
+		switch {
+		case quote != 0 && c == '\\':
+			x++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '/' && x+1 < len(src) && src[x+1] == '/':
+			for x < len(src) && src[x] != '\n' {
+				x++
+			}
+		case c == '(' || c == '[' || c == '{':
+			n++
+		case c == ')' || c == ']' || c == '}':
+			n--
+		}
+	}
+	return n
+}
+
+// Repl reads input from r and writes prompts, values and errors to w until :quit or the end of r
+func Repl(r io.Reader, w io.Writer) {
+	in := New(r, w)
+	s := NewSession(in)
+
+	defer in.Stdout.Flush()
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(exit); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	in.Stdout.WriteString("Volant REPL, enter declarations, statements or expressions, :quit to exit\n")
+
+	for {
+		src, ok := read(in, "> ")
+		for ok && depth(src) > 0 {
+			var more string
+			more, ok = read(in, "... ")
+			src += more
+		}
+
+		trimmed := strings.TrimSpace(src)
+		switch {
+		case !ok && trimmed == "":
+			in.Stdout.WriteString("\n")
+			return
+		case trimmed == ":quit" || trimmed == ":q":
+			return
+		case trimmed == "":
+			continue
+		}
+
+		var out string
+		if err := error.Catch(func() { out = s.Eval(src + ";") }); err != nil {
+			out = err.Error()
+		}
+		if out != "" {
+			in.Stdout.WriteString(out + "\n")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func read(in *Interpreter, prompt string) (string, bool) {
+	in.Stdout.WriteString(prompt)
+	in.Stdout.Flush()
+
+	line, err := in.Stdin.ReadString('\n')
+	return line, err == nil
+}
@@ -0,0 +1,116 @@
+package interp
+
+import (
+	"bytes"
+	. "parser"
+	"strconv"
+)
+
+func strValue(length uint64, mem uint64) value {
+	b := make([]byte, strType.size)
+	intValue(sizeType, length).encode(b[:8])
+	value{typ: strType.fields[1].typ, i: mem}.encode(b[8:])
+	return value{typ: strType, b: b}
+}
+
+// strFields returns the length and the address of the bytes of a str stored at addr
+func (in *Interpreter) strFields(addr uint64, node positioned) (uint64, uint64) {
+	return in.load(addr, sizeType, node).i, in.load(addr+8, strType.fields[1].typ, node).i
+}
+
+// strBytes returns the bytes of s
+func (in *Interpreter) strBytes(s value, node positioned) []byte {
+	length, mem := decode(sizeType, s.b[:8]).i, decode(strType.fields[1].typ, s.b[8:]).i
+	if length == 0 {
+		return nil
+	}
+	b := in.mem.bytes(mem, int(length))
+	if b == nil {
+		in.error("Invalid memory access at 0x"+strconv.FormatUint(mem, 16)+".", node)
+	}
+	return b
+}
+
+// cStr converts the C string at addr to a str, like STR_FROM_CSTRING
+func (in *Interpreter) cStr(addr uint64, node positioned) value {
+	if addr == 0 {
+		return strValue(0, 0)
+	}
+	n := uint64(0)
+	for ; ; n++ {
+		b := in.mem.bytes(addr+n, 1)
+		if b == nil {
+			in.error("Invalid memory access at 0x"+strconv.FormatUint(addr+n, 16)+".", node)
+		}
+		if b[0] == 0 {
+			break
+		}
+	}
+	return strValue(n, addr)
+}
+
+// strIndex returns the address of a byte of the str stored at addr
+func (in *Interpreter) strIndex(addr uint64, n int64, node positioned) uint64 {
+	length, mem := in.strFields(addr, node)
+	if n < 0 || uint64(n) >= length {
+		in.error("Index "+strconv.FormatInt(n, 10)+" out of range for a str of length "+strconv.FormatUint(length, 10)+".", node)
+	}
+	return mem + uint64(n)
+}
+
+// strMethod calls a method of the str stored at addr, the methods are the macros of str.h
+func (in *Interpreter) strMethod(addr uint64, name string, args []value, node positioned) value {
+	length, mem := in.strFields(addr, node)
+
+	switch name {
+	case "slice":
+		in.arity(args, 2, node)
+		start, end := in.convert(args[0], sizeType, node).i, in.convert(args[1], sizeType, node).i
+		if start > end || end > length {
+			in.error("Slice ["+strconv.FormatUint(start, 10)+":"+strconv.FormatUint(end, 10)+"] out of range for a str of length "+strconv.FormatUint(length, 10)+".", node)
+		}
+		return strValue(end-start, mem+start)
+	case "cstr":
+		in.arity(args, 0, node)
+		buf := in.alloc(arrayOf(u8Type, int(length)+1), node)
+		if length > 0 {
+			copy(in.mem.bytes(buf, int(length)), in.mem.bytes(mem, int(length)))
+		}
+		return value{typ: strType.fields[1].typ, i: buf}
+	}
+	in.error("str has no method called '"+name+"'.", node)
+	return void
+}
+
+// compareStrs compares strs with op like STR_COMPARE, by their bytes
+func (in *Interpreter) compareStrs(op Token, l value, r value, node positioned) value {
+	c := bytes.Compare(in.strBytes(l, node), in.strBytes(r, node))
+	return in.binary(op, intValue(i32Type, uint64(int64(c))), intValue(i32Type, 0), node)
+}
+
+// length evaluates len, the length of a str or the number of elements of an array
+func (in *Interpreter) length(expr LenExpr, sc *scope) value {
+	var t *rtype
+
+	switch expr.Type.(type) {
+	case BasicType:
+		switch name := expr.Type.(BasicType).Expr; name.(type) {
+		case IdentExpr:
+			if _, ok := sc.lookup(string(name.(IdentExpr).Value.Buff)).(*variable); ok {
+				v := in.eval(name, sc)
+				if v.typ == strType {
+					return value{typ: sizeType, i: decode(sizeType, v.b[:8]).i}
+				}
+				t = v.typ
+			}
+		}
+	}
+	if t == nil {
+		t = in.resolve(expr.Type, sc)
+	}
+
+	if t.kind == arrayKind {
+		return value{typ: sizeType, i: uint64(t.length)}
+	}
+	return value{typ: sizeType, i: uint64(t.size)}
+}
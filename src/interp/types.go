@@ -0,0 +1,366 @@
+package interp
+
+import (
+	. "parser"
+	"strconv"
+	"strings"
+)
+
+type kind byte
+
+const (
+	voidKind kind = iota
+	intKind       // integers, bool and enums
+	floatKind
+	pointerKind
+	arrayKind
+	structKind
+	tupleKind
+	unionKind
+	funcKind    // handles of closures
+	vecKind     // handles of vectors
+	promiseKind // handles of promises
+	listKind    // array literals, they take the type they are converted to
+)
+
+// rtype is a resolved type, with the layout its values have in memory
+type rtype struct {
+	kind     kind
+	name     string
+	size     int
+	align    int
+	signed   bool
+	elem     *rtype // pointers, arrays, vectors and promises
+	length   int    // arrays, -1 until an implicit array is initialized
+	fields   []field
+	methods  map[string]*closure
+	args     []*rtype // functions
+	ret      *rtype
+	members  []string // enums
+	values   map[string]int64
+	env      *scope // structs, default values are evaluated where the struct was declared
+	defaults []byte
+}
+
+type field struct {
+	name   string
+	typ    *rtype
+	offset int
+	value  Expression // the default value of struct fields
+}
+
+func intType(name string, size int, signed bool) *rtype {
+	return &rtype{kind: intKind, name: name, size: size, align: size, signed: signed}
+}
+
+var (
+	voidType = &rtype{kind: voidKind, name: "void", size: 1, align: 1}
+	i8Type   = intType("i8", 1, true)
+	i16Type  = intType("i16", 2, true)
+	i32Type  = intType("i32", 4, true)
+	i64Type  = intType("i64", 8, true)
+	u8Type   = intType("u8", 1, false)
+	u16Type  = intType("u16", 2, false)
+	u32Type  = intType("u32", 4, false)
+	u64Type  = intType("u64", 8, false)
+	sizeType = intType("size_t", 8, false)
+	uptrType = intType("uptr", 8, false)
+	boolType = intType("bool", 1, false)
+	f32Type  = &rtype{kind: floatKind, name: "f32", size: 4, align: 4}
+	f64Type  = &rtype{kind: floatKind, name: "f64", size: 8, align: 8}
+	listType = &rtype{kind: listKind, name: "array literal"}
+
+	// strType is laid out like the str of str.h, the bytes it points to are not its own
+	strType = &rtype{kind: structKind, name: "str", size: 16, align: 8, fields: []field{
+		{name: "length", typ: sizeType},
+		{name: "mem", typ: pointerTo(u8Type), offset: 8},
+	}}
+)
+
+var builtinTypes = []*rtype{voidType, i8Type, i16Type, i32Type, i64Type, u8Type, u16Type, u32Type, u64Type, sizeType, uptrType, boolType, f32Type, f64Type, strType}
+
+// cTypes are the C types that can be named with $, in the order of types.h
+var cTypes = map[string]*rtype{
+	"void": voidType, "char": i8Type, "short": i16Type, "int": i32Type, "long": i64Type, "size_t": sizeType,
+	"float": f32Type, "double": f64Type, "bool": boolType, "uptr": uptrType,
+	"i8": i8Type, "i16": i16Type, "i32": i32Type, "i64": i64Type,
+	"u8": u8Type, "u16": u16Type, "u32": u32Type, "u64": u64Type, "f32": f32Type, "f64": f64Type,
+}
+
+func pointerTo(t *rtype) *rtype {
+	return &rtype{kind: pointerKind, size: 8, align: 8, elem: t}
+}
+
+func vecOf(t *rtype) *rtype {
+	return &rtype{kind: vecKind, size: 8, align: 8, elem: t}
+}
+
+func promiseOf(t *rtype) *rtype {
+	return &rtype{kind: promiseKind, size: 8, align: 8, elem: t}
+}
+
+func arrayOf(t *rtype, length int) *rtype {
+	return &rtype{kind: arrayKind, size: t.size * length, align: t.align, elem: t, length: length}
+}
+
+var voidPointer = pointerTo(voidType)
+
+func (t *rtype) String() string {
+	if t.name != "" {
+		return t.name
+	}
+	switch t.kind {
+	case pointerKind:
+		return "*" + t.elem.String()
+	case arrayKind:
+		if t.length < 0 {
+			return "[]" + t.elem.String()
+		}
+		return "[" + strconv.Itoa(t.length) + "]" + t.elem.String()
+	case vecKind:
+		return "vec " + t.elem.String()
+	case promiseKind:
+		return "promise " + t.elem.String()
+	case funcKind:
+		args := []string{}
+		for _, arg := range t.args {
+			args = append(args, arg.String())
+		}
+		if t.ret.kind == voidKind {
+			return "func(" + strings.Join(args, ", ") + ")"
+		}
+		return "func(" + strings.Join(args, ", ") + ") " + t.ret.String()
+	case structKind:
+		return "struct"
+	case tupleKind:
+		return "tuple"
+	case unionKind:
+		return "union"
+	}
+	return "?"
+}
+
+// isScalar reports whether values of t are stored in value.i or value.f
+func (t *rtype) isScalar() bool {
+	switch t.kind {
+	case arrayKind, structKind, tupleKind, unionKind, listKind:
+		return false
+	}
+	return true
+}
+
+func (t *rtype) field(name string) (field, bool) {
+	for _, f := range t.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// layout places fields one after the other like a C compiler does, union members all start at 0
+func (t *rtype) layout() {
+	t.size, t.align = 0, 1
+
+	for x := range t.fields {
+		f := &t.fields[x]
+		if f.typ.align > t.align {
+			t.align = f.typ.align
+		}
+		if t.kind == unionKind {
+			f.offset = 0
+			if f.typ.size > t.size {
+				t.size = f.typ.size
+			}
+			continue
+		}
+		f.offset = align(t.size, f.typ.align)
+		t.size = f.offset + f.typ.size
+	}
+	t.size = align(t.size, t.align)
+}
+
+// resolve returns the layout of typ, names in it are looked up in sc
+func (in *Interpreter) resolve(typ Type, sc *scope) *rtype {
+	switch typ.(type) {
+	case BasicType:
+		return in.namedType(typ.(BasicType).Expr, sc)
+	case PointerType:
+		return pointerTo(in.resolve(typ.(PointerType).BaseType, sc))
+	case ConstType:
+		return in.resolve(typ.(ConstType).BaseType, sc)
+	case StaticType:
+		return in.resolve(typ.(StaticType).BaseType, sc)
+	case CaptureType:
+		return in.resolve(typ.(CaptureType).BaseType, sc)
+	case VecType:
+		return vecOf(in.resolve(typ.(VecType).BaseType, sc))
+	case PromiseType:
+		return promiseOf(in.resolve(typ.(PromiseType).BaseType, sc))
+	case ArrayType:
+		length, err := strconv.ParseInt(string(typ.(ArrayType).Size.Buff), 0, 32)
+		if err != nil || length < 0 {
+			in.error("Invalid array size '"+string(typ.(ArrayType).Size.Buff)+"'.", typ)
+		}
+		return arrayOf(in.resolve(typ.(ArrayType).BaseType, sc), int(length))
+	case ImplictArrayType:
+		return arrayOf(in.resolve(typ.(ImplictArrayType).BaseType, sc), -1)
+	case FuncType:
+		return in.funcType(typ.(FuncType), sc)
+	case StructType:
+		t := &rtype{kind: structKind}
+		in.strct(t, typ.(StructType), sc)
+		return t
+	case TupleType:
+		return in.tuple(typ.(TupleType), sc)
+	case UnionType:
+		return in.union(typ.(UnionType), sc)
+	case EnumType:
+		return in.enum(typ.(EnumType), sc)
+	case NumberType:
+		return i32Type
+	}
+	in.error("Unsupported type.", typ)
+	return nil
+}
+
+// namedType resolves the name of a type, a C type if it starts with $ or a type exported from a module
+func (in *Interpreter) namedType(expr Expression, sc *scope) *rtype {
+	switch expr.(type) {
+	case IdentExpr:
+		name := string(expr.(IdentExpr).Value.Buff)
+		if strings.HasPrefix(name, "$") {
+			if t, ok := cTypes[name[1:]]; ok {
+				return t
+			}
+			in.error("C type '"+name+"' is not available in the interpreter.", expr)
+		}
+		switch b := sc.lookup(name); b.(type) {
+		case *typeName:
+			return b.(*typeName).typ
+		case nil:
+			in.error("Use of undeclared type '"+name+"'.", expr)
+		}
+	case MemberExpr:
+		if m := in.moduleOf(expr.(MemberExpr).Base, sc); m != nil {
+			switch b := m.scope.names[string(expr.(MemberExpr).Prop.Buff)]; b.(type) {
+			case *typeName:
+				return b.(*typeName).typ
+			}
+		}
+	}
+	in.error("Expression is not a type.", expr)
+	return nil
+}
+
+func (in *Interpreter) funcType(typ FuncType, sc *scope) *rtype {
+	t := &rtype{kind: funcKind, size: 8, align: 8, ret: voidType}
+
+	for _, arg := range typ.ArgTypes {
+		if a := in.resolve(arg, sc); a.kind != voidKind {
+			t.args = append(t.args, a)
+		}
+	}
+	if len(typ.ReturnTypes) > 0 {
+		t.ret = in.resolve(typ.ReturnTypes[0], sc)
+	}
+	return t
+}
+
+// isMethod reports whether a struct prop declares a method, methods take no space in structs
+func isMethod(prop Declaration) bool {
+	if len(prop.Values) != 1 || len(prop.Types) != 1 {
+		return false
+	}
+	switch prop.Types[0].(type) {
+	case FuncType:
+		switch prop.Values[0].(type) {
+		case FuncExpr:
+			return true
+		}
+	}
+	return false
+}
+
+// strct fills t, which may already be referred to by the fields of the struct, props of super structs come after its own
+func (in *Interpreter) strct(t *rtype, typ StructType, sc *scope) {
+	t.env = sc
+	t.methods = map[string]*closure{}
+
+	for _, prop := range typ.Props {
+		if isMethod(prop) {
+			t.methods[string(prop.Identifiers[0].Buff)] = &closure{fnc: prop.Values[0].(FuncExpr), env: sc, path: sc.path()}
+			continue
+		}
+		for x, ident := range prop.Identifiers {
+			f := field{name: string(ident.Buff)}
+
+			if x < len(prop.Values) {
+				f.value = prop.Values[x]
+			}
+			switch {
+			case len(prop.Types) == 1:
+				f.typ = in.resolve(prop.Types[0], sc)
+			case x < len(prop.Types):
+				f.typ = in.resolve(prop.Types[x], sc)
+			case f.value != nil:
+				f.typ = in.declType(in.eval(f.value, sc), f.value, sc)
+			}
+			t.fields = append(t.fields, f)
+		}
+	}
+
+	for _, super := range typ.SuperStructs {
+		st := in.namedType(super, sc)
+		if st.kind != structKind {
+			in.error("Expected a struct, got "+st.String()+".", super)
+		}
+		t.fields = append(t.fields, st.fields...)
+
+		for name, m := range st.methods {
+			if _, ok := t.methods[name]; !ok {
+				t.methods[name] = &closure{fnc: m.fnc, env: m.env, path: m.path, self: t}
+			}
+		}
+	}
+	t.layout()
+}
+
+func (in *Interpreter) tuple(typ TupleType, sc *scope) *rtype {
+	t := &rtype{kind: tupleKind}
+	for x, el := range typ.Types {
+		t.fields = append(t.fields, field{name: strconv.Itoa(x), typ: in.resolve(el, sc)})
+	}
+	t.layout()
+	return t
+}
+
+func (in *Interpreter) union(typ UnionType, sc *scope) *rtype {
+	t := &rtype{kind: unionKind}
+	for x, ident := range typ.Identifiers {
+		t.fields = append(t.fields, field{name: string(ident.Buff), typ: in.resolve(typ.Types[x], sc)})
+	}
+	t.layout()
+	return t
+}
+
+// enum numbers its members like C does, from 0 or from the last explicit value
+func (in *Interpreter) enum(typ EnumType, sc *scope) *rtype {
+	t := intType("", 4, true)
+	t.values = map[string]int64{}
+	next := int64(0)
+
+	for x, ident := range typ.Identifiers {
+		if x < len(typ.Values) && typ.Values[x] != nil {
+			v := in.eval(typ.Values[x], sc)
+			if v.typ.kind != intKind {
+				in.error("Enum values must be integers.", typ.Values[x])
+			}
+			next = v.int()
+		}
+		t.members = append(t.members, string(ident.Buff))
+		t.values[string(ident.Buff)] = next
+		next++
+	}
+	return t
+}
@@ -0,0 +1,384 @@
+package interp
+
+import (
+	"encoding/binary"
+	"math"
+	. "parser"
+)
+
+// value is a value of type typ, scalars are kept in i or f and aggregates as the bytes they have in memory
+type value struct {
+	typ  *rtype
+	i    uint64 // integers, pointers and handles, signed integers are sign extended
+	f    float64
+	b    []byte  // arrays, structs, tuples and unions
+	list []value // array literals
+}
+
+var void = value{typ: voidType}
+
+func intValue(t *rtype, n uint64) value {
+	return value{typ: t, i: wrap(t, n)}
+}
+
+func floatValue(t *rtype, f float64) value {
+	if t.size == 4 {
+		f = float64(float32(f))
+	}
+	return value{typ: t, f: f}
+}
+
+// wrap truncates n to the size of t like C does on overflow, signed integers are sign extended
+func wrap(t *rtype, n uint64) uint64 {
+	if t.size >= 8 || t.kind != intKind {
+		return n
+	}
+	bits := uint(t.size * 8)
+	n &= 1<<bits - 1
+	if t.signed && n&(1<<(bits-1)) != 0 {
+		n |= ^uint64(0) << bits
+	}
+	return n
+}
+
+func (v value) int() int64 {
+	if v.typ.kind == floatKind {
+		return int64(v.f)
+	}
+	return int64(v.i)
+}
+
+// encode writes v to b, which is v.typ.size bytes long
+func (v value) encode(b []byte) {
+	switch v.typ.kind {
+	case voidKind, listKind:
+		break
+	case floatKind:
+		if v.typ.size == 4 {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v.f)))
+		} else {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v.f))
+		}
+	case arrayKind, structKind, tupleKind, unionKind:
+		copy(b, v.b)
+	default:
+		for x := range b {
+			b[x] = byte(v.i >> (8 * uint(x)))
+		}
+	}
+}
+
+func decode(t *rtype, b []byte) value {
+	switch t.kind {
+	case voidKind:
+		return value{typ: t}
+	case floatKind:
+		if t.size == 4 {
+			return value{typ: t, f: float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))}
+		}
+		return value{typ: t, f: math.Float64frombits(binary.LittleEndian.Uint64(b))}
+	case arrayKind, structKind, tupleKind, unionKind:
+		return value{typ: t, b: append([]byte{}, b...)}
+	}
+	n := uint64(0)
+	for x := range b {
+		n |= uint64(b[x]) << (8 * uint(x))
+	}
+	return intValue(t, n)
+}
+
+// isWord reports whether values of t are an integer, a pointer or a handle
+func isWord(t *rtype) bool {
+	switch t.kind {
+	case intKind, pointerKind, funcKind, vecKind, promiseKind:
+		return true
+	}
+	return false
+}
+
+// convert converts v to t like an assignment in C does, array literals are converted to arrays and vectors
+func (in *Interpreter) convert(v value, t *rtype, node positioned) value {
+	if v.typ.kind == arrayKind && t.kind != arrayKind {
+		v = in.decay(v, node)
+	}
+
+	switch t.kind {
+	case voidKind:
+		return value{typ: t}
+	case intKind:
+		switch {
+		case v.typ.kind == floatKind && t.signed:
+			return intValue(t, uint64(int64(v.f)))
+		case v.typ.kind == floatKind:
+			return intValue(t, uint64(v.f))
+		case isWord(v.typ):
+			return intValue(t, v.i)
+		}
+	case floatKind:
+		switch {
+		case v.typ.kind == floatKind:
+			return floatValue(t, v.f)
+		case v.typ.kind == intKind && v.typ.signed:
+			return floatValue(t, float64(int64(v.i)))
+		case v.typ.kind == intKind:
+			return floatValue(t, float64(v.i))
+		}
+	case pointerKind, funcKind, promiseKind:
+		if isWord(v.typ) {
+			return value{typ: t, i: v.i}
+		}
+	case vecKind:
+		if isWord(v.typ) {
+			return value{typ: t, i: v.i}
+		}
+		if v.typ.kind == listKind {
+			elems := make([]value, len(v.list))
+			for x, el := range v.list {
+				elems[x] = in.convert(el, t.elem, node)
+			}
+			return in.newVector(t, elems)
+		}
+	case arrayKind:
+		if v.typ.kind == listKind {
+			return in.listToArray(v, t, node)
+		}
+		if v.typ.kind == arrayKind && t.length < 0 {
+			return value{typ: v.typ, b: v.b}
+		}
+		// shorter arrays are padded with zeros, like strings in C
+		if v.typ.kind == arrayKind && v.typ.elem.size == t.elem.size && v.typ.size <= t.size {
+			b := make([]byte, t.size)
+			copy(b, v.b)
+			return value{typ: t, b: b}
+		}
+	case structKind, tupleKind, unionKind:
+		if t == strType && v.typ.kind == pointerKind {
+			return in.cStr(v.i, node)
+		}
+		if v.typ == t || v.typ.kind == t.kind && v.typ.size == t.size {
+			return value{typ: t, b: v.b}
+		}
+		if v.typ.kind == listKind && t.kind != unionKind {
+			return in.listToStruct(v, t, node)
+		}
+	}
+	in.error("Cannot convert "+v.typ.String()+" to "+t.String()+".", node)
+	return void
+}
+
+func (in *Interpreter) listToArray(v value, t *rtype, node positioned) value {
+	if t.length < 0 {
+		t = arrayOf(t.elem, len(v.list))
+	}
+	if len(v.list) > t.length {
+		in.error("Too many elements for "+t.String()+".", node)
+	}
+	b := make([]byte, t.size)
+	for x, el := range v.list {
+		in.convert(el, t.elem, node).encode(b[x*t.elem.size : (x+1)*t.elem.size])
+	}
+	return value{typ: t, b: b}
+}
+
+func (in *Interpreter) listToStruct(v value, t *rtype, node positioned) value {
+	if len(v.list) > len(t.fields) {
+		in.error("Too many values for "+t.String()+".", node)
+	}
+	b := make([]byte, t.size)
+	for x, el := range v.list {
+		f := t.fields[x]
+		in.convert(el, f.typ, node).encode(b[f.offset : f.offset+f.typ.size])
+	}
+	return value{typ: t, b: b}
+}
+
+// arithType is the type C converts the operands of an arithmetic operator to
+func arithType(a *rtype, b *rtype) *rtype {
+	if a.kind == floatKind || b.kind == floatKind {
+		if a.kind == floatKind && a.size == 8 || b.kind == floatKind && b.size == 8 {
+			return f64Type
+		}
+		return f32Type
+	}
+	size, signed := a.size, a.signed
+	switch {
+	case b.size > size:
+		size, signed = b.size, b.signed
+	case b.size == size:
+		signed = signed && b.signed
+	}
+	switch {
+	case size < 4:
+		return i32Type
+	case size == 4 && signed:
+		return i32Type
+	case size == 4:
+		return u32Type
+	case signed:
+		return i64Type
+	}
+	return u64Type
+}
+
+// boolValue is the result of comparisons and logical operators, an int in C and a bool for the analyzer
+func boolValue(b bool) value {
+	if b {
+		return value{typ: boolType, i: 1}
+	}
+	return value{typ: boolType}
+}
+
+// truthy reports whether v is not zero, conditions in C are true when they are not zero
+func (in *Interpreter) truthy(v value, node positioned) bool {
+	switch {
+	case v.typ.kind == floatKind:
+		return v.f != 0
+	case isWord(v.typ):
+		return v.i != 0
+	}
+	in.error("Expected a number or a pointer, got "+v.typ.String()+".", node)
+	return false
+}
+
+// binary applies an arithmetic, relational or bitwise operator, && and || are evaluated in eval because they short-circuit
+func (in *Interpreter) binary(op Token, l value, r value, node positioned) value {
+	lk, rk := l.typ.kind, r.typ.kind
+
+	if l.typ == strType || r.typ == strType {
+		switch op.SecondaryType {
+		case EqualEqual, NotEqual, Less, Greater, LessEqual, GreaterEqual:
+			return in.compareStrs(op, in.convert(l, strType, node), in.convert(r, strType, node), node)
+		}
+	}
+
+	// pointer arithmetic moves by the size of the element, like in C
+	switch {
+	case lk == pointerKind && rk == intKind && (op.SecondaryType == Add || op.SecondaryType == Sub):
+		n := r.int() * int64(l.typ.elem.size)
+		if op.SecondaryType == Sub {
+			n = -n
+		}
+		return value{typ: l.typ, i: l.i + uint64(n)}
+	case lk == intKind && rk == pointerKind && op.SecondaryType == Add:
+		return value{typ: r.typ, i: r.i + uint64(l.int()*int64(r.typ.elem.size))}
+	case lk == pointerKind && rk == pointerKind && op.SecondaryType == Sub:
+		return value{typ: i64Type, i: uint64((int64(l.i) - int64(r.i)) / int64(l.typ.elem.size))}
+	}
+
+	if !l.typ.isScalar() || !r.typ.isScalar() {
+		in.error("Invalid operands "+l.typ.String()+" and "+r.typ.String()+" for '"+string(op.Buff)+"'.", node)
+	}
+
+	// pointers and handles are only compared
+	if lk != intKind && lk != floatKind || rk != intKind && rk != floatKind {
+		switch op.SecondaryType {
+		case EqualEqual:
+			return boolValue(l.i == r.i)
+		case NotEqual:
+			return boolValue(l.i != r.i)
+		case Less:
+			return boolValue(l.i < r.i)
+		case Greater:
+			return boolValue(l.i > r.i)
+		case LessEqual:
+			return boolValue(l.i <= r.i)
+		case GreaterEqual:
+			return boolValue(l.i >= r.i)
+		}
+		in.error("Invalid operands "+l.typ.String()+" and "+r.typ.String()+" for '"+string(op.Buff)+"'.", node)
+	}
+
+	t := arithType(l.typ, r.typ)
+	l, r = in.convert(l, t, node), in.convert(r, t, node)
+
+	if t.kind == floatKind {
+		switch op.SecondaryType {
+		case Add:
+			return floatValue(t, l.f+r.f)
+		case Sub:
+			return floatValue(t, l.f-r.f)
+		case Mul:
+			return floatValue(t, l.f*r.f)
+		case Div:
+			return floatValue(t, l.f/r.f)
+		case EqualEqual:
+			return boolValue(l.f == r.f)
+		case NotEqual:
+			return boolValue(l.f != r.f)
+		case Less:
+			return boolValue(l.f < r.f)
+		case Greater:
+			return boolValue(l.f > r.f)
+		case LessEqual:
+			return boolValue(l.f <= r.f)
+		case GreaterEqual:
+			return boolValue(l.f >= r.f)
+		}
+		in.error("Invalid operands "+l.typ.String()+" and "+r.typ.String()+" for '"+string(op.Buff)+"'.", node)
+	}
+
+	a, b := l.i, r.i
+	switch op.SecondaryType {
+	case Add:
+		return intValue(t, a+b)
+	case Sub:
+		return intValue(t, a-b)
+	case Mul:
+		return intValue(t, a*b)
+	case Div, Modulus:
+		if b == 0 {
+			in.error("Division by zero.", node)
+		}
+		if op.SecondaryType == Div && t.signed {
+			return intValue(t, uint64(int64(a)/int64(b)))
+		} else if op.SecondaryType == Div {
+			return intValue(t, a/b)
+		} else if t.signed {
+			return intValue(t, uint64(int64(a)%int64(b)))
+		}
+		return intValue(t, a%b)
+	case LeftShift:
+		return intValue(t, a<<(b&63))
+	case RightShift:
+		if t.signed {
+			return intValue(t, uint64(int64(a)>>(b&63)))
+		}
+		return intValue(t, a>>(b&63))
+	case Or:
+		return intValue(t, a|b)
+	case And:
+		return intValue(t, a&b)
+	case ExclusiveOr:
+		return intValue(t, a^b)
+	case EqualEqual:
+		return boolValue(a == b)
+	case NotEqual:
+		return boolValue(a != b)
+	}
+
+	if t.signed {
+		switch op.SecondaryType {
+		case Less:
+			return boolValue(int64(a) < int64(b))
+		case Greater:
+			return boolValue(int64(a) > int64(b))
+		case LessEqual:
+			return boolValue(int64(a) <= int64(b))
+		case GreaterEqual:
+			return boolValue(int64(a) >= int64(b))
+		}
+	} else {
+		switch op.SecondaryType {
+		case Less:
+			return boolValue(a < b)
+		case Greater:
+			return boolValue(a > b)
+		case LessEqual:
+			return boolValue(a <= b)
+		case GreaterEqual:
+			return boolValue(a >= b)
+		}
+	}
+	in.error("Unknown operator '"+string(op.Buff)+"'.", node)
+	return void
+}
@@ -0,0 +1,202 @@
+package lsp
+
+import (
+	"bytes"
+	"compiler"
+	"error"
+	"io/ioutil"
+	"net/url"
+	. "parser"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type document struct {
+	uri  string
+	path string
+	text []byte
+
+	// results of the last analysis of text that parsed, kept while the text doesn't parse so
+	// hovers and completions keep working in the middle of an edit
+	ast     File
+	uses    map[compiler.Position]compiler.Use
+	imports map[string]*compiler.SymbolTable
+}
+
+func newDocument(uri string) *document {
+	return &document{uri: uri, path: uriToPath(uri)}
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// analyze parses and analyzes the document, the first error found is returned as a diagnostic
+func (doc *document) analyze() *Diagnostic {
+	compiler.NoEmit = true
+	compiler.Uses = map[compiler.Position]compiler.Use{}
+	defer func() {
+		compiler.Uses = nil
+	}()
+
+	var ast File
+	parsed := false
+
+	err := error.Catch(func() {
+		ast = ParseFile(&Lexer{Buffer: doc.text, Line: 1, Column: 1, Path: doc.path})
+		parsed = true
+		_, doc.imports, _, _, _ = compiler.AnalyzeFile(ast, doc.path)
+	})
+
+	if parsed {
+		doc.ast = ast
+		doc.uses = compiler.Uses
+	}
+	if err == nil {
+		return nil
+	}
+	return doc.diagnostic(err)
+}
+
+func (doc *document) diagnostic(err *error.Error) *Diagnostic {
+	d := &Diagnostic{Severity: SeverityError, Source: "volant", Message: err.Message}
+
+	if err.Line > 0 && (err.Path == "" || err.Path == doc.path) {
+		d.Range = doc.wordRange(err.Line, err.Column)
+		return d
+	}
+
+	// errors in imported files are shown on the import
+	d.Message = err.Error()
+	for _, stmt := range doc.ast.Statements {
+		switch stmt.(type) {
+		case Import:
+			for _, path := range stmt.(Import).Paths {
+				name := strings.Trim(string(path.Buff), "\"")
+				if err.Path != "" && filepath.Base(name) == filepath.Base(err.Path) || err.Path == "" && strings.Contains(err.Message, string(path.Buff)) {
+					d.Range = Range{
+						Start: doc.position(doc.path, path.Line, path.Column),
+						End:   doc.position(doc.path, path.Line, path.Column+len(path.Buff)),
+					}
+					return d
+				}
+			}
+		}
+	}
+	return d
+}
+
+// line returns the text of line (1 based) of the file at path, using the document's text for the document itself
+func (doc *document) line(path string, line int) []byte {
+	text := doc.text
+	if path != doc.path {
+		code, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		text = code
+	}
+
+	lines := bytes.Split(text, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	return lines[line-1]
+}
+
+// utf16Len returns the length of text in UTF-16 code units, which LSP positions are measured in
+func utf16Len(text []byte) int {
+	n := 0
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		n += len(utf16.Encode([]rune{r}))
+		text = text[size:]
+	}
+	return n
+}
+
+// position converts a 1 based line and byte column in the file at path to an LSP position
+func (doc *document) position(path string, line, column int) Position {
+	text := doc.line(path, line)
+	if column-1 < len(text) {
+		text = text[:column-1]
+	}
+	return Position{Line: line - 1, Character: utf16Len(text)}
+}
+
+// column converts an LSP position to a 1 based byte column
+func (doc *document) column(pos Position) int {
+	text := doc.line(doc.path, pos.Line+1)
+	column, n := 1, 0
+
+	for len(text) > 0 && n < pos.Character {
+		r, size := utf8.DecodeRune(text)
+		n += len(utf16.Encode([]rune{r}))
+		column += size
+		text = text[size:]
+	}
+	return column
+}
+
+// identStart returns the byte offset the identifier that ends at end in text starts at, identifiers can be unicode
+func identStart(text []byte, end int) int {
+	for end > 0 {
+		r, size := utf8.DecodeLastRune(text[:end])
+		if !IsIdentifierRune(r) {
+			break
+		}
+		end -= size
+	}
+	return end
+}
+
+// identEnd returns the byte offset the identifier that starts at start in text ends at
+func identEnd(text []byte, start int) int {
+	for start < len(text) {
+		r, size := utf8.DecodeRune(text[start:])
+		if !IsIdentifierRune(r) {
+			break
+		}
+		start += size
+	}
+	return start
+}
+
+// word returns the identifier around the 1 based byte column on line and the column it starts at
+func (doc *document) word(path string, line, column int) ([]byte, int) {
+	text := doc.line(path, line)
+	start, end := column-1, column-1
+
+	if start > len(text) {
+		return nil, column
+	}
+	start, end = identStart(text, start), identEnd(text, end)
+	return text[start:end], start + 1
+}
+
+// wordRange returns the range from line and column to the end of the word there, or of a single character if there is none
+func (doc *document) wordRange(line, column int) Range {
+	return doc.pathWordRange(doc.path, line, column)
+}
+
+func (doc *document) pathWordRange(path string, line, column int) Range {
+	word, wordStart := doc.word(path, line, column)
+	start := doc.position(path, line, column)
+	end := doc.position(path, line, wordStart+len(word))
+
+	if end.Character <= start.Character {
+		end = start
+		end.Character++
+	}
+	return Range{Start: start, End: end}
+}
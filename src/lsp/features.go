@@ -0,0 +1,361 @@
+package lsp
+
+import (
+	"bytes"
+	"compiler"
+	"error"
+	. "parser"
+	"printer"
+)
+
+// useAt returns what the analyzer resolved the identifier at pos to
+func (doc *document) useAt(pos Position) (compiler.Use, []byte, int, bool) {
+	word, column := doc.word(doc.path, pos.Line+1, doc.column(pos))
+	if len(word) == 0 {
+		return compiler.Use{}, nil, 0, false
+	}
+	use, ok := doc.uses[compiler.Position{Path: doc.path, Line: pos.Line + 1, Column: column}]
+	return use, word, column, ok
+}
+
+func (doc *document) definition(pos Position) *Location {
+	use, _, _, ok := doc.useAt(pos)
+	if !ok || use.Decl.Line == 0 {
+		return nil
+	}
+	return &Location{URI: pathToURI(use.Decl.Path), Range: doc.pathWordRange(use.Decl.Path, use.Decl.Line, use.Decl.Column)}
+}
+
+func (doc *document) hover(pos Position) *Hover {
+	use, word, column, ok := doc.useAt(pos)
+	if !ok || use.Type == nil {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```volant\n" + describe(word, use.Type) + "\n```"},
+		Range:    doc.wordRange(pos.Line+1, column),
+	}
+}
+
+// describe returns the declaration of name with type typ
+func describe(name []byte, typ Type) string {
+	switch typ.(type) {
+	case Typedef:
+		if bytes.Compare(typ.(Typedef).Name.Buff, name) == 0 {
+			return string(printer.PrintTypedef(typ.(Typedef)))
+		}
+	case FuncType:
+		if !typ.(FuncType).Mut {
+			return string(printer.PrintFuncHead(Token{Buff: name}, typ.(FuncType)))
+		}
+	case NumberType:
+		return string(name) + ": number"
+	case InternalType:
+		return string(name)
+	}
+	return string(name) + ": " + string(printer.PrintType(typ))
+}
+
+func (doc *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, stmt := range doc.ast.Statements {
+		symbols = append(symbols, doc.statementSymbols(stmt)...)
+	}
+	return symbols
+}
+
+func (doc *document) statementSymbols(stmt Statement) []DocumentSymbol {
+	switch stmt.(type) {
+	case ExportStatement:
+		return doc.statementSymbols(stmt.(ExportStatement).Stmt)
+	case Declaration:
+		return doc.declarationSymbols(stmt.(Declaration), false)
+	case Typedef:
+		return []DocumentSymbol{doc.typedefSymbol(stmt.(Typedef))}
+	case Test:
+		test := stmt.(Test)
+		return []DocumentSymbol{{
+			Name:           "test " + string(test.Name.Buff),
+			Kind:           FunctionSymbol,
+			Range:          doc.span(test.Line, test.Column, test.Name, test.Block.EndLine),
+			SelectionRange: doc.wordRange(test.Line, test.Column),
+		}}
+	}
+	return nil
+}
+
+func (doc *document) declarationSymbols(dec Declaration, member bool) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	for x, ident := range dec.Identifiers {
+		kind, endLine := VariableSymbol, ident.Line
+		var typ Type
+
+		if len(dec.Types) == 1 {
+			typ = dec.Types[0]
+		} else if x < len(dec.Types) {
+			typ = dec.Types[x]
+		}
+		if x < len(dec.Values) {
+			switch dec.Values[x].(type) {
+			case FuncExpr:
+				fnc := dec.Values[x].(FuncExpr)
+				kind, typ, endLine = FunctionSymbol, fnc.Type, fnc.Block.EndLine
+			}
+		}
+		switch typ.(type) {
+		case ConstType:
+			kind = ConstantSymbol
+		}
+		if member {
+			if kind == FunctionSymbol {
+				kind = MethodSymbol
+			} else {
+				kind = FieldSymbol
+			}
+		}
+
+		symbol := DocumentSymbol{
+			Name:           string(ident.Buff),
+			Kind:           kind,
+			Range:          doc.span(dec.Line, dec.Column, ident, endLine),
+			SelectionRange: doc.wordRange(ident.Line, ident.Column),
+		}
+		if typ != nil {
+			symbol.Detail = string(printer.PrintType(typ))
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+func (doc *document) typedefSymbol(typedef Typedef) DocumentSymbol {
+	name := typedef.Name
+	symbol := DocumentSymbol{Name: string(name.Buff), Kind: TypeSymbol, SelectionRange: doc.wordRange(name.Line, name.Column)}
+	endLine := name.Line
+
+	switch typedef.Type.(type) {
+	case StructType:
+		symbol.Kind = StructSymbol
+		for _, prop := range typedef.Type.(StructType).Props {
+			symbol.Children = append(symbol.Children, doc.declarationSymbols(prop, true)...)
+		}
+		endLine = typedef.Type.(StructType).EndLine
+	case UnionType:
+		symbol.Kind = StructSymbol
+		for x, ident := range typedef.Type.(UnionType).Identifiers {
+			symbol.Children = append(symbol.Children, DocumentSymbol{
+				Name:           string(ident.Buff),
+				Detail:         string(printer.PrintType(typedef.Type.(UnionType).Types[x])),
+				Kind:           FieldSymbol,
+				Range:          doc.span(ident.Line, ident.Column, ident, ident.Line),
+				SelectionRange: doc.wordRange(ident.Line, ident.Column),
+			})
+			endLine = ident.Line
+		}
+	case EnumType:
+		symbol.Kind = EnumSymbol
+		for _, ident := range typedef.Type.(EnumType).Identifiers {
+			symbol.Children = append(symbol.Children, DocumentSymbol{
+				Name:           string(ident.Buff),
+				Kind:           EnumMemberSymbol,
+				Range:          doc.span(ident.Line, ident.Column, ident, ident.Line),
+				SelectionRange: doc.wordRange(ident.Line, ident.Column),
+			})
+			endLine = ident.Line
+		}
+	default:
+		symbol.Detail = string(printer.PrintType(typedef.Type))
+	}
+
+	symbol.Range = doc.span(typedef.Line, typedef.Column, name, endLine)
+	return symbol
+}
+
+// span returns the range from line and column to the end of endLine, starting at ident if line is unknown
+func (doc *document) span(line, column int, ident Token, endLine int) Range {
+	if line == 0 || line > ident.Line {
+		line, column = ident.Line, ident.Column
+	}
+	if endLine < line {
+		endLine = line
+	}
+	return Range{
+		Start: doc.position(doc.path, line, column),
+		End:   doc.position(doc.path, endLine, len(doc.line(doc.path, endLine))+1),
+	}
+}
+
+func (doc *document) completion(pos Position) CompletionList {
+	list := CompletionList{Items: []CompletionItem{}}
+
+	chain, column := doc.memberChain(pos)
+	if len(chain) == 0 {
+		return list
+	}
+
+	// types that fail to resolve just leave the list empty
+	error.Catch(func() {
+		list.Items = append(list.Items, doc.members(chain, pos.Line+1, column)...)
+	})
+	return list
+}
+
+// memberChain returns the identifiers in "a.b.c." before pos and the column of the first,
+// an identifier being typed after the last dot is left for the client to filter on
+func (doc *document) memberChain(pos Position) ([][]byte, int) {
+	text := doc.line(doc.path, pos.Line+1)
+	end := doc.column(pos) - 1
+	if end > len(text) {
+		end = len(text)
+	}
+
+	end = identStart(text, end)
+
+	chain := [][]byte{}
+	for end > 0 && text[end-1] == '.' {
+		start := identStart(text, end-1)
+		if start == end-1 {
+			return nil, 0
+		}
+		chain = append([][]byte{text[start : end-1]}, chain...)
+		end = start
+	}
+
+	if len(chain) == 0 || chain[0][0] >= '0' && chain[0][0] <= '9' {
+		return nil, 0
+	}
+	return chain, end + 1
+}
+
+// lookup returns the closest use of the identifier name declared or used before line and column,
+// or the first one after it for globals declared further down
+func (doc *document) lookup(name []byte, line, column int) (compiler.Use, bool) {
+	var best, after compiler.Use
+	var bestPos, afterPos compiler.Position
+	found, foundAfter := false, false
+
+	for pos, use := range doc.uses {
+		if pos.Path != doc.path || use.Prop || bytes.Compare(use.Name, name) != 0 {
+			continue
+		}
+		if pos.Line < line || pos.Line == line && pos.Column < column {
+			if !found || before(bestPos, pos) {
+				best, bestPos, found = use, pos, true
+			}
+		} else if !foundAfter || before(pos, afterPos) {
+			after, afterPos, foundAfter = use, pos, true
+		}
+	}
+
+	if found {
+		return best, true
+	}
+	return after, foundAfter
+}
+
+func before(a, b compiler.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// anyUse returns a use in the outermost scope of the document, its scope is enough to resolve imported types
+func (doc *document) anyUse() (compiler.Use, bool) {
+	var first compiler.Use
+	var firstPos compiler.Position
+	found := false
+
+	for pos, use := range doc.uses {
+		if pos.Path == doc.path && (!found || before(pos, firstPos)) {
+			first, firstPos, found = use, pos, true
+		}
+	}
+	return first, found
+}
+
+func (doc *document) members(chain [][]byte, line, column int) []CompletionItem {
+	use, ok := doc.lookup(chain[0], line, column)
+	typ := use.Type
+
+	if !ok {
+		exports, ok := doc.imports[string(chain[0])]
+		if !ok {
+			return nil
+		}
+		if len(chain) == 1 {
+			return exportItems(exports)
+		}
+
+		node, ok := exports.Find(Token{Buff: chain[1]})
+		if !ok {
+			return nil
+		}
+		if use, ok = doc.anyUse(); !ok {
+			return nil
+		}
+		typ, chain = node.Type, chain[1:]
+	}
+
+	for _, name := range chain[1:] {
+		node, ok := use.Member(typ, name)
+		if !ok {
+			return nil
+		}
+		typ = node.Type
+	}
+
+	kind := FieldCompletion
+	switch use.RootType(typ).(type) {
+	case EnumType:
+		kind = EnumMemberCompletion
+	}
+
+	items := []CompletionItem{}
+	for _, node := range use.Members(typ) {
+		item := CompletionItem{Label: string(node.Identifier.Buff), Kind: kind, Detail: string(printer.PrintType(node.Type))}
+		switch node.Type.(type) {
+		case FuncType:
+			item.Kind = MethodCompletion
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func exportItems(exports *compiler.SymbolTable) []CompletionItem {
+	items := []CompletionItem{}
+
+	for _, node := range exports.Nodes {
+		item := CompletionItem{Label: string(node.Identifier.Buff), Kind: VariableCompletion}
+
+		switch node.Type.(type) {
+		case FuncType:
+			item.Kind = FunctionCompletion
+			item.Detail = string(printer.PrintType(node.Type))
+		case Typedef:
+			item.Kind = StructCompletion
+		default:
+			item.Detail = string(printer.PrintType(node.Type))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// format returns an edit replacing the whole document with its canonical source, none when it is already formatted
+// or does not parse
+func (doc *document) format() []TextEdit {
+	var ast File
+	if err := error.Catch(func() {
+		ast = ParseFile(&Lexer{Buffer: doc.text, Line: 1, Column: 1, Path: doc.path, KeepComments: true})
+	}); err != nil {
+		return []TextEdit{}
+	}
+
+	formatted := printer.PrintFile(ast)
+	if bytes.Equal(formatted, doc.text) {
+		return []TextEdit{}
+	}
+	lines := bytes.Split(doc.text, []byte("\n"))
+	end := Position{Line: len(lines) - 1, Character: utf16Len(lines[len(lines)-1])}
+	return []TextEdit{{Range: Range{End: end}, NewText: string(formatted)}}
+}
@@ -0,0 +1,84 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"compiler"
+	"fmt"
+	"internal/golden"
+	"io"
+	"io/ioutil"
+	"lsp"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestTranscripts sends the messages in testdata/*.jsonl, one per line, to a server and compares every message it
+// writes back, and its exit code, with the .golden file next to them
+func TestTranscripts(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.jsonl")
+	if len(files) == 0 {
+		t.Fatal("no transcripts found")
+	}
+	// the modules the transcripts import are next to them
+	dir, _ := filepath.Abs("testdata")
+	compiler.ImportPaths = []string{dir}
+	defer func() {
+		compiler.ImportPaths = nil
+	}()
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			script, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			in := &bytes.Buffer{}
+			for _, line := range strings.Split(string(script), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(line), line)
+				}
+			}
+			out := &bytes.Buffer{}
+			code := lsp.NewServer(in, out).Serve()
+
+			got := &bytes.Buffer{}
+			for _, msg := range messages(t, out) {
+				got.Write(msg)
+				got.WriteByte('\n')
+			}
+			fmt.Fprintf(got, "exit %d\n", code)
+
+			golden.Check(t, strings.TrimSuffix(file, ".jsonl")+".golden", got.Bytes())
+		})
+	}
+}
+
+// messages splits what the server wrote into the bodies of its messages
+func messages(t *testing.T, out io.Reader) [][]byte {
+	r := bufio.NewReader(out)
+	bodies := [][]byte{}
+
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err == io.EOF {
+			return bodies
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatalf("invalid Content-Length %q", header.Get("Content-Length"))
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			t.Fatal(err)
+		}
+		bodies = append(bodies, body)
+	}
+}
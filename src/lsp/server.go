@@ -0,0 +1,246 @@
+// Package lsp implements a language server for volant over stdio
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+// Logger receives the server's own errors, stdout is reserved for the protocol
+var Logger = log.New(os.Stderr, "volant lsp: ", 0)
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, docs: map[string]*document{}}
+}
+
+// Serve handles messages until the client sends exit or closes the stream, and returns the exit code
+func (s *Server) Serve() int {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return 1
+		}
+		if err != nil {
+			Logger.Println(err)
+			return 1
+		}
+
+		req := request{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: parseError, Message: err.Error()})
+			continue
+		}
+		if req.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		s.handle(req)
+	}
+}
+
+// read reads the body of the next message
+func (s *Server) read() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) write(msg interface{}) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		Logger.Println(err)
+		return
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rErr *responseError) {
+	res := response{JSONRPC: "2.0", ID: id, Error: rErr}
+	if rErr == nil {
+		res.Result, _ = json.Marshal(result)
+	}
+	s.write(res)
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) handle(req request) {
+	defer func() {
+		if r := recover(); r != nil {
+			Logger.Printf("panic handling %s: %v\n%s", req.Method, r, debug.Stack())
+			if req.ID != nil {
+				s.reply(req.ID, nil, &responseError{Code: internalError, Message: fmt.Sprint(r)})
+			}
+		}
+	}()
+
+	result, rErr := s.dispatch(req)
+
+	// notifications get no response
+	if req.ID != nil {
+		s.reply(req.ID, result, rErr)
+	}
+}
+
+func (s *Server) dispatch(req request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":           1, // full
+				"hoverProvider":              true,
+				"definitionProvider":         true,
+				"documentSymbolProvider":     true,
+				"documentFormattingProvider": true,
+				"completionProvider":         map[string]interface{}{"triggerCharacters": []string{"."}},
+			},
+			"serverInfo": map[string]string{"name": "volant"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		params := DidOpenTextDocumentParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		params := DidChangeTextDocumentParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if l := len(params.ContentChanges); l > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[l-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		params := DidSaveTextDocumentParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if params.Text != nil {
+			s.update(params.TextDocument.URI, *params.Text)
+		} else if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			// imported files may have changed on disk
+			s.update(doc.uri, string(doc.text))
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		params := DidCloseTextDocumentParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		return nil, nil
+
+	case "textDocument/definition":
+		params := TextDocumentPositionParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			return doc.definition(params.Position), nil
+		}
+		return nil, nil
+	case "textDocument/hover":
+		params := TextDocumentPositionParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			return doc.hover(params.Position), nil
+		}
+		return nil, nil
+	case "textDocument/documentSymbol":
+		params := DocumentSymbolParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			return doc.symbols(), nil
+		}
+		return []DocumentSymbol{}, nil
+	case "textDocument/completion":
+		params := TextDocumentPositionParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			return doc.completion(params.Position), nil
+		}
+		return CompletionList{Items: []CompletionItem{}}, nil
+	case "textDocument/formatting":
+		params := DocumentFormattingParams{}
+		if rErr := unmarshal(req.Params, &params); rErr != nil {
+			return nil, rErr
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			return doc.format(), nil
+		}
+		return []TextEdit{}, nil
+	}
+
+	if strings.HasPrefix(req.Method, "$/") || req.ID == nil {
+		return nil, nil
+	}
+	return nil, &responseError{Code: methodNotFound, Message: "method not supported: " + req.Method}
+}
+
+func unmarshal(params json.RawMessage, v interface{}) *responseError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// update replaces the text of the document at uri, analyzes it and publishes its diagnostics
+func (s *Server) update(uri string, text string) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		doc = newDocument(uri)
+		s.docs[uri] = doc
+	}
+	doc.text = []byte(text)
+
+	diagnostics := []Diagnostic{}
+	if d := doc.analyze(); d != nil {
+		diagnostics = append(diagnostics, *d)
+	}
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
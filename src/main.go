@@ -1,42 +1,827 @@
 package main
 
 import (
+	"bytes"
 	. "compiler"
+	"doc"
+	"dump"
+	diag "error"
+	"errors"
 	"flag"
 	"fmt"
+	"interp"
+	"io/ioutil"
+	"lsp"
 	"os"
 	"os/exec"
+	"parser"
 	"path"
+	"path/filepath"
+	"printer"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
 var exPath, _ = os.Executable()
 var libPath = path.Join(path.Dir(exPath), "../lib")
 var defaultH = path.Join(libPath, "internal/default.h")
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("no arguments given")
-		os.Exit(1)
+// command is a volant subcommand, run receives the arguments after the command name
+type command struct {
+	name  string
+	usage string
+	desc  string
+	run   func(args []string)
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"build", "build [flags] file.vo", "compile file.vo and its imports to an executable, file.vo is read from stdin when it is -", buildCmd},
+		{"compile", "compile [flags] file.vo", "compile file.vo and its imports to a.out next to file.vo, or to an object file with -c", compileCmd},
+		{"run", "run [flags] file.vo [-- args...]", "build file.vo and run it, passing args to the program", runCmd},
+		{"test", "test [flags] [path...]", "build and run the test blocks of files, directories are searched for _test.vo files", testCmd},
+		{"check", "check [flags] file.vo...", "parse and analyze files and their imports without generating C", checkCmd},
+		{"emit-c", "emit-c [flags] file.vo", "print the C generated for file.vo, or write it and its imports to a directory", emitCmd},
+		{"fmt", "fmt [flags] path...", "format Volant source files, directories are searched for .vo files", fmtCmd},
+		{"doc", "doc [flags] [path...]", "render the exported API of modules as Markdown or HTML, the standard library by default", docCmd},
+		{"dump-ast", "dump-ast [flags] file.vo", "print the AST, the symbol tables and the formatted AST of file.vo, for debugging the compiler", dumpCmd},
+		{"interp", "interp [flags] file.vo", "run file.vo with the interpreter, without compiling it to C", interpCmd},
+		{"repl", "repl [flags]", "evaluate declarations, statements and expressions line by line", replCmd},
+		{"lsp", "lsp [flags]", "run the language server, speaking the Language Server Protocol over stdin and stdout", lspCmd},
+		{"help", "help [command]", "show help for a command", helpCmd},
 	}
-	switch os.Args[1] {
-	case "compile":
-		if len(os.Args) < 3 {
-			fmt.Println("file name not given")
+}
+
+// pathList collects repeated -I flags
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, string(os.PathListSeparator))
+}
+
+func (p *pathList) Set(dir string) error {
+	*p = append(*p, dir)
+	return nil
+}
+
+func registerIncludes(cmd *flag.FlagSet, p *pathList) {
+	cmd.Var(p, "I", "add a `directory` to the import search path (can be repeated)")
+}
+
+// buildOptions are the flags shared by every command that invokes the C compiler
+type buildOptions struct {
+	out      string
+	opt      string
+	cc       string
+	target   string
+	ccflags  string
+	keepC    bool
+	verbose  bool
+	includes pathList
+}
+
+func (o *buildOptions) register(cmd *flag.FlagSet) {
+	cmd.StringVar(&o.out, "o", "", "write the output to `file`")
+	cmd.StringVar(&o.opt, "O", "", "optimization `level` passed to the C compiler (0-3), -O2 is the same as -O=2")
+	cmd.StringVar(&o.cc, "cc", "clang", "C `compiler` to invoke")
+	cmd.StringVar(&o.target, "target", "", "target `triple` passed to the C compiler")
+	cmd.StringVar(&o.ccflags, "ccflags", "", "extra `flags` passed to the C compiler")
+	cmd.StringVar(&o.ccflags, "clang", "", "deprecated alias for -ccflags")
+	cmd.BoolVar(&o.keepC, "keep-c", false, "keep the generated C in _build next to file.vo")
+	cmd.BoolVar(&o.verbose, "verbose", false, "print the C compiler invocation")
+	registerIncludes(cmd, &o.includes)
+}
+
+func fatal(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+
+func lookup(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	c := lookup(name)
+	cmd := flag.NewFlagSet(name, flag.ExitOnError)
+
+	cmd.Usage = func() {
+		out := cmd.Output()
+		fmt.Fprintf(out, "usage: volant %s\n\n%s\n\nflags:\n", c.usage, c.desc)
+		cmd.PrintDefaults()
+	}
+	return cmd
+}
+
+var optFlag = regexp.MustCompile(`^--?O([0-9])$`)
+
+// parseArgs parses flags appearing anywhere in args and returns the positional arguments
+func parseArgs(cmd *flag.FlagSet, args []string) []string {
+	rewritten := make([]string, len(args))
+
+	for i, arg := range args {
+		if m := optFlag.FindStringSubmatch(arg); m != nil {
+			arg = "-O=" + m[1]
+		}
+		rewritten[i] = arg
+	}
+
+	positional := []string{}
+	args = rewritten
+
+	for {
+		cmd.Parse(args)
+		args = cmd.Args()
+
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional
+}
+
+// sourceFile returns the single source file given to cmd
+func sourceFile(cmd *flag.FlagSet, positional []string) string {
+	if len(positional) != 1 {
+		fmt.Fprintln(cmd.Output(), "expected exactly one file.vo")
+		cmd.Usage()
+		os.Exit(2)
+	}
+	return path.Clean(positional[0])
+}
+
+// importMain compiles file and its imports, the error that stopped compilation is returned instead of exiting
+// so that the build directories can be removed
+func importMain(file string) *diag.Error {
+	return diag.Catch(func() {
+		ImportFile(path.Dir(file), path.Base(file), true, 0)
+	})
+}
+
+// translate writes the C for file and its imports, returns the path of the main C file, a cleanup func and the error
+// that stopped compilation, the cleanup func has to be called either way
+func translate(file string, o *buildOptions) (string, func(), *diag.Error) {
+	ImportPaths = append(ImportPaths, o.includes...)
+	cleanup := func() {}
+
+	if !o.keepC {
+		tmp, err := ioutil.TempDir("", "volant")
+		if err != nil {
+			fatal("error creating build directory: " + err.Error())
+		}
+		BuildDir = path.Join(tmp, "_build")
+		cleanup = func() { os.RemoveAll(tmp) }
+	} else {
+		// the importer puts _build next to file, the directory of a file translated before is not reused
+		BuildDir = ""
+	}
+
+	err := importMain(file)
+	return path.Join(BuildDir, "0"+path.Base(file)+".c"), cleanup, err
+}
+
+// cc invokes the C compiler on the generated C, objects are produced instead of executables when link is false
+func cc(cFile string, o *buildOptions, link bool) error {
+	args := []string{cFile, "-pthread", "-fblocks", "-I" + libPath}
+
+	if o.opt != "" {
+		if len(o.opt) != 1 || o.opt[0] < '0' || o.opt[0] > '3' {
+			return fmt.Errorf("invalid optimization level %q, expected 0-3", o.opt)
+		}
+		args = append(args, "-O"+o.opt)
+	}
+	if o.target != "" {
+		args = append(args, "--target="+o.target)
+	}
+	if !link {
+		args = append(args, "-c")
+	}
+	args = append(args, strings.Fields(o.ccflags)...)
+	args = append(args, "-o", o.out)
+
+	if link {
+		args = append(args, "-luv", "-lBlocksRuntime", "-lgc")
+	}
+
+	if o.verbose {
+		fmt.Fprintln(os.Stderr, o.cc+" "+strings.Join(args, " "))
+	}
+
+	cmd := exec.Command(o.cc, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// trimExt returns file without its extension
+func trimExt(file string) string {
+	return strings.TrimSuffix(file, path.Ext(file))
+}
+
+// buildOrCompile links an executable named after file for build, compile writes a.out next to file like it always did
+// unless it is given -c, which writes an object file instead
+func buildOrCompile(name string, args []string) {
+	o := buildOptions{}
+	object := false
+	cmd := newFlagSet(name)
+	o.register(cmd)
+	if name == "compile" {
+		cmd.BoolVar(&object, "c", false, "write an object file instead of linking an executable")
+	}
+	file := sourceFile(cmd, parseArgs(cmd, args))
+	link := !object
+
+	if o.out == "" {
+		o.out = trimExt(file)
+		if file == "-" {
+			o.out = "stdin"
 		}
+		if object {
+			o.out += ".o"
+		} else if name == "compile" {
+			o.out = path.Join(path.Dir(file), "a.out")
+		}
+	}
+
+	cFile, cleanup, diagErr := translate(file, &o)
+	if diagErr != nil {
+		cleanup()
+		fatal(diagErr.Error())
+	}
+	err := cc(cFile, &o, link)
+	cleanup()
+
+	if err != nil {
+		fatal(err.Error())
+	}
+}
+
+func buildCmd(args []string) {
+	buildOrCompile("build", args)
+}
+
+func compileCmd(args []string) {
+	buildOrCompile("compile", args)
+}
+
+// splitArgs splits args at the first "--", everything after it is passed to the program
+func splitArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, []string{}
+}
+
+func runCmd(args []string) {
+	args, progArgs := splitArgs(args)
+
+	o := buildOptions{}
+	cmd := newFlagSet("run")
+	o.register(cmd)
+	file := sourceFile(cmd, parseArgs(cmd, args))
+
+	tmp, err := ioutil.TempDir("", "volant-run")
+	if err != nil {
+		fatal("error creating build directory: " + err.Error())
+	}
+	if o.out == "" {
+		o.out = path.Join(tmp, "a.out")
+	}
+
+	cFile, cleanup, diagErr := translate(file, &o)
+	if diagErr != nil {
+		cleanup()
+		os.RemoveAll(tmp)
+		fatal(diagErr.Error())
+	}
+	err = cc(cFile, &o, true)
+	cleanup()
+
+	if err != nil {
+		os.RemoveAll(tmp)
+		fatal(err.Error())
+	}
+
+	prog := exec.Command(o.out, progArgs...)
+	prog.Stdin = os.Stdin
+	prog.Stdout = os.Stdout
+	prog.Stderr = os.Stderr
+
+	err = prog.Run()
+	os.RemoveAll(tmp)
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitCode(exitErr))
+	} else if err != nil {
+		fatal("error running program: " + err.Error())
+	}
+}
+
+// exitCode is the exit status of a program that failed, 128 plus the signal like the shells when it was killed by one
+func exitCode(err *exec.ExitError) int {
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		fmt.Fprintln(os.Stderr, "program killed by signal: "+status.Signal().String())
+		return 128 + int(status.Signal())
+	}
+	return err.ExitCode()
+}
+
+// testFiles returns the files in paths and the _test.vo files in the directories in paths
+func testFiles(paths []string) []string {
+	files := []string{}
+
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		for _, file := range voFiles([]string{p}) {
+			if strings.HasSuffix(file, "_test.vo") {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+func testCmd(args []string) {
+	o := buildOptions{}
+	run := ""
+
+	cmd := newFlagSet("test")
+	o.register(cmd)
+	cmd.StringVar(&run, "run", "", "only run the tests whose name contains `substring`")
+	paths := parseArgs(cmd, args)
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	tmp, err := ioutil.TempDir("", "volant-test")
+	if err != nil {
+		fatal("error creating build directory: " + err.Error())
+	}
+	defer os.RemoveAll(tmp)
+
+	files := testFiles(paths)
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "no test files found")
+		return
+	}
+
+	EmitTests = true
+	ImportPaths = append(ImportPaths, o.includes...)
+	o.includes = nil
+	failed := false
+
+	for x, file := range files {
+		file = path.Clean(file)
+		fmt.Println("== " + file)
+
+		opts := o
+		opts.out = path.Join(tmp, "test"+strconv.Itoa(x))
+
+		cFile, cleanup, diagErr := translate(file, &opts)
+		if diagErr != nil {
+			cleanup()
+			fmt.Fprintln(os.Stderr, "error building "+file+": "+diagErr.Error())
+			failed = true
+			continue
+		}
+		err := cc(cFile, &opts, true)
+		cleanup()
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error building "+file+": "+err.Error())
+			failed = true
+			continue
+		}
+
+		prog := exec.Command(opts.out, run)
+		prog.Stdout = os.Stdout
+		prog.Stderr = os.Stderr
+
+		if err := prog.Run(); err != nil {
+			failed = true
+		}
+	}
 
-		cmd := flag.NewFlagSet("compile", flag.ExitOnError)
-		clang := cmd.String("clang", "", "pass arguments to the clang compiler")
+	if failed {
+		os.RemoveAll(tmp)
+		os.Exit(1)
+	}
+}
 
-		file := path.Clean(os.Args[2])
-		cmd.Parse(os.Args[3:])
+func checkCmd(args []string) {
+	includes := pathList{}
+	cmd := newFlagSet("check")
+	registerIncludes(cmd, &includes)
+	files := parseArgs(cmd, args)
+
+	if len(files) == 0 {
+		fmt.Fprintln(cmd.Output(), "no files given")
+		cmd.Usage()
+		os.Exit(2)
+	}
 
+	ImportPaths = append(ImportPaths, includes...)
+	NoEmit = true
+
+	for _, file := range files {
+		file = path.Clean(file)
 		ImportFile(path.Dir(file), path.Base(file), true, 0)
+	}
+}
+
+func emitCmd(args []string) {
+	includes := pathList{}
+	out := ""
+	imports := false
+
+	cmd := newFlagSet("emit-c")
+	cmd.StringVar(&out, "o", "", "write the generated C for file.vo and its imports to `directory` instead of stdout")
+	cmd.BoolVar(&imports, "imports", false, "also print the C generated for imported files")
+	registerIncludes(cmd, &includes)
+	file := sourceFile(cmd, parseArgs(cmd, args))
+
+	ImportPaths = append(ImportPaths, includes...)
+
+	if out != "" {
+		BuildDir = out
+		ImportFile(path.Dir(file), path.Base(file), true, 0)
+		return
+	}
+
+	if err := printC(file, imports); err != nil {
+		fatal(err.Error())
+	}
+}
+
+// printC prints the C generated for file, and for its imports when imports is set, the C is written to a temporary
+// directory that is removed before returning
+func printC(file string, imports bool) error {
+	tmp, err := ioutil.TempDir("", "volant")
+	if err != nil {
+		return fmt.Errorf("error creating build directory: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	BuildDir = tmp
+	if err := importMain(file); err != nil {
+		return errors.New(err.Error())
+	}
+	mainC := path.Join(tmp, "0"+path.Base(file)+".c")
+
+	files := []string{}
+	if imports {
+		filepath.Walk(tmp, func(p string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && p != mainC {
+				files = append(files, p)
+			}
+			return nil
+		})
+	}
+	files = append(files, mainC)
+
+	for i, f := range files {
+		code, err := ioutil.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("error reading generated C: %v", err)
+		}
+		if imports {
+			if i > 0 {
+				fmt.Println()
+			}
+			rel, _ := filepath.Rel(tmp, f)
+			fmt.Println("// " + rel)
+		}
+		os.Stdout.Write(code)
+	}
+	return nil
+}
+
+// voFiles replaces the directories in paths with the .vo files in them
+func voFiles(paths []string) []string {
+	files := []string{}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			fatal(err.Error())
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		// Walk doesn't follow a symlinked root
+		if root, err := filepath.EvalSymlinks(p); err == nil {
+			p = root
+		}
+
+		filepath.Walk(p, func(file string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && path.Ext(file) == ".vo" {
+				files = append(files, file)
+			}
+			return nil
+		})
+	}
+
+	return files
+}
+
+func fmtCmd(args []string) {
+	check := false
+	write := false
+
+	cmd := newFlagSet("fmt")
+	cmd.BoolVar(&check, "check", false, "list the files which are not formatted and exit with status 1 if there are any")
+	cmd.BoolVar(&write, "write", false, "write the result to the source files instead of stdout")
+	paths := parseArgs(cmd, args)
+
+	if len(paths) == 0 {
+		fmt.Fprintln(cmd.Output(), "no files given")
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	unformatted := false
+
+	for _, file := range voFiles(paths) {
+		code, err := ioutil.ReadFile(file)
+		if err != nil {
+			fatal("error reading file: " + err.Error())
+		}
+
+		ast := parser.ParseFile(&parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: file, KeepComments: true})
+		formatted := printer.PrintFile(ast)
+
+		switch {
+		case check:
+			if !bytes.Equal(code, formatted) {
+				fmt.Println(file)
+				unformatted = true
+			}
+		case write:
+			if !bytes.Equal(code, formatted) {
+				if err := ioutil.WriteFile(file, formatted, 0644); err != nil {
+					fatal("error writing file: " + err.Error())
+				}
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if unformatted {
+		os.Exit(1)
+	}
+}
+
+// moduleFiles returns the .vo files of the modules in p, the test files in a directory are not modules
+func moduleFiles(p string) []string {
+	files := []string{}
+	for _, file := range voFiles([]string{p}) {
+		if p == file || !strings.HasSuffix(file, "_test.vo") {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func docCmd(args []string) {
+	html := false
+	out := ""
+
+	cmd := newFlagSet("doc")
+	cmd.BoolVar(&html, "html", false, "render static HTML instead of Markdown")
+	cmd.StringVar(&out, "o", "", "write a file per module and an index to `directory` instead of stdout")
+	paths := parseArgs(cmd, args)
+
+	if len(paths) == 0 {
+		paths = []string{libPath}
+	}
+
+	modules := []doc.Module{}
+	failed := false
 
-		out, err := exec.Command("/bin/bash", "-c", "clang " + path.Join(path.Dir(file), "_build", "0"+path.Base(file)+".c") + " -pthread " + "-luv" + " -fblocks " + " -lBlocksRuntime " + " -lgc " + " -I" + libPath + " " + *clang + " -o " + path.Join(path.Dir(file), "a.out")).CombinedOutput()
-		
+	for _, p := range paths {
+		info, err := os.Stat(p)
 		if err != nil {
-			fmt.Println(string(out))
-			os.Exit(1)
+			fatal(err.Error())
+		}
+		if root, err := filepath.EvalSymlinks(p); err == nil {
+			p = root
+		}
+
+		for _, file := range moduleFiles(p) {
+			importPath := path.Base(file)
+			if info.IsDir() {
+				importPath, _ = filepath.Rel(p, file)
+			}
+
+			m, err := doc.Load(file, filepath.ToSlash(importPath))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "skipping "+file+": "+err.Error())
+				failed = true
+				continue
+			}
+			modules = append(modules, m)
 		}
 	}
+	doc.Sort(modules)
+
+	if out == "" {
+		if html {
+			os.Stdout.Write(doc.HTML("Modules", "", modules))
+		} else {
+			os.Stdout.Write(doc.Markdown(modules))
+		}
+	} else {
+		ext, index := ".md", doc.MarkdownIndex(modules)
+		if html {
+			ext, index = ".html", doc.HTMLIndex(modules)
+		}
+
+		write := func(file string, content []byte) {
+			file = filepath.Join(out, filepath.FromSlash(file))
+			os.MkdirAll(filepath.Dir(file), 0755)
+			if err := ioutil.WriteFile(file, content, 0644); err != nil {
+				fatal("error writing file: " + err.Error())
+			}
+		}
+
+		write("index"+ext, index)
+		for _, m := range modules {
+			file := m.File(ext)
+			if html {
+				root := strings.Repeat("../", strings.Count(file, "/"))
+				if root == "" {
+					root = "./"
+				}
+				write(file, doc.HTML(m.Name, root, []doc.Module{m}))
+			} else {
+				write(file, doc.Markdown([]doc.Module{m}))
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func interpCmd(args []string) {
+	includes := pathList{}
+	cmd := newFlagSet("interp")
+	registerIncludes(cmd, &includes)
+	file := sourceFile(cmd, parseArgs(cmd, args))
+
+	ImportPaths = append(ImportPaths, includes...)
+	ProjectDir = path.Dir(file)
+
+	os.Exit(interp.RunFile(interp.New(os.Stdin, os.Stdout), file))
+}
+
+func replCmd(args []string) {
+	includes := pathList{}
+	cmd := newFlagSet("repl")
+	registerIncludes(cmd, &includes)
+
+	if len(parseArgs(cmd, args)) != 0 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+	ImportPaths = append(ImportPaths, includes...)
+
+	interp.Repl(os.Stdin, os.Stdout)
+}
+
+func dumpCmd(args []string) {
+	includes := pathList{}
+	asJSON := false
+	only := ""
+
+	cmd := newFlagSet("dump-ast")
+	cmd.BoolVar(&asJSON, "json", false, "print JSON instead of indented text")
+	cmd.StringVar(&only, "only", "", "print only one `stage`: ast, symbols or formatted")
+	registerIncludes(cmd, &includes)
+	file := sourceFile(cmd, parseArgs(cmd, args))
+
+	switch only {
+	case "", "ast", "symbols", "formatted":
+	default:
+		fmt.Fprintln(cmd.Output(), "unknown stage "+only)
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	code, err := ioutil.ReadFile(file)
+	if err != nil {
+		fatal("error reading file: " + err.Error())
+	}
+
+	ImportPaths = append(ImportPaths, includes...)
+	ProjectDir = path.Dir(file)
+
+	stages, dumpErr := dump.Run(code, file)
+	if only != "" {
+		selected := []dump.Stage{}
+		for _, stage := range stages {
+			if stage.Name == only {
+				selected = append(selected, stage)
+			}
+		}
+		stages = selected
+	}
+
+	if asJSON {
+		os.Stdout.Write(dump.JSON(stages))
+	} else {
+		for x, stage := range stages {
+			if x > 0 {
+				fmt.Println()
+			}
+			fmt.Println("== " + stage.Name + " ==")
+			os.Stdout.Write(dump.Text(stage.Value))
+		}
+	}
+
+	if dumpErr != nil {
+		fatal(dumpErr.Error())
+	}
+}
+
+func lspCmd(args []string) {
+	includes := pathList{}
+	cmd := newFlagSet("lsp")
+	registerIncludes(cmd, &includes)
+
+	if len(parseArgs(cmd, args)) != 0 {
+		fmt.Fprintln(cmd.Output(), "lsp takes no arguments")
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	ImportPaths = append(ImportPaths, includes...)
+	os.Exit(lsp.NewServer(os.Stdin, os.Stdout).Serve())
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: volant <command> [flags] [arguments]\n\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s%s\n", c.name, c.desc)
+	}
+	fmt.Fprintln(os.Stderr, "\nrun \"volant help <command>\" for more information about a command")
+}
+
+func helpCmd(args []string) {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" {
+		usage()
+		return
+	}
+
+	c := lookup(args[0])
+	if c == nil {
+		fatal("unknown command \"" + args[0] + "\"")
+	}
+
+	c.run([]string{"-h"})
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "-h", "-help", "--help":
+		usage()
+		return
+	}
+
+	c := lookup(os.Args[1])
+	if c == nil {
+		fmt.Fprintln(os.Stderr, "unknown command \""+os.Args[1]+"\"")
+		usage()
+		os.Exit(2)
+	}
+
+	c.run(os.Args[2:])
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"compiler"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModuleFiles(t *testing.T) {
+	files := moduleFiles("../lib")
+	modules := map[string]bool{}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.vo") {
+			t.Errorf("%s is listed as a module", file)
+		}
+		modules[filepath.Base(file)] = true
+	}
+	if !modules["fs.vo"] || !modules["uv.vo"] {
+		t.Errorf("fs.vo and uv/uv.vo are missing from %v", files)
+	}
+
+	// a test file that is asked for by name is documented
+	if files := moduleFiles("../lib/fs_test.vo"); len(files) != 1 {
+		t.Errorf("got %v for a test file named on the command line", files)
+	}
+}
+
+func TestTranslateKeepC(t *testing.T) {
+	defer func() { compiler.BuildDir = "" }()
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	for _, dir := range dirs {
+		file := filepath.Join(dir, "main.vo")
+		if err := ioutil.WriteFile(file, []byte("func main() i32 {\n    return 0;\n}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cFile, cleanup, err := translate(file, &buildOptions{keepC: true})
+		cleanup()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if want := filepath.Join(dir, "_build", "0main.vo.c"); cFile != want {
+			t.Errorf("want %s, got %s", want, cFile)
+		}
+		if _, err := os.Stat(cFile); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	err := exec.Command("sh", "-c", "kill -9 $$").Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("want an exit error, got %v", err)
+	}
+	if code := exitCode(exitErr); code != 128+9 {
+		t.Errorf("want 137 for a program killed by SIGKILL, got %d", code)
+	}
+
+	exitErr = exec.Command("sh", "-c", "exit 3").Run().(*exec.ExitError)
+	if code := exitCode(exitErr); code != 3 {
+		t.Errorf("want 3, got %d", code)
+	}
+}
+
+func TestPrintCRemovesBuildDir(t *testing.T) {
+	defer func() { compiler.BuildDir = "" }()
+
+	tmp := t.TempDir()
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	os.Setenv("TMPDIR", tmp)
+
+	file := filepath.Join(t.TempDir(), "broken.vo")
+	if err := ioutil.WriteFile(file, []byte("func main() i32 {\n    return x;\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := printC(file, false); err == nil {
+		t.Fatal("want an error for an undefined variable")
+	}
+	if left, _ := ioutil.ReadDir(tmp); len(left) != 0 {
+		t.Errorf("the build directory %s is left behind", left[0].Name())
+	}
+}
@@ -64,6 +64,8 @@ type Type interface {
 type File struct {
 	Path       string
 	Statements []Statement
+	Comments   []Comment
+	BlankLines []int
 }
 
 type (
@@ -71,11 +73,13 @@ type (
 		Statements []Statement
 		Line       int
 		Column     int
+		EndLine    int
 	}
 	Declaration struct {
 		Identifiers []Token
 		Types       []Type
 		Values      []Expression
+		Doc         []Comment
 		Line        int
 		Column      int
 	}
@@ -139,6 +143,7 @@ type (
 		DefaultName Token
 		Type        Type
 		NameSpace   Token
+		Doc         []Comment
 		Line        int
 		Column      int
 	}
@@ -161,6 +166,19 @@ type (
 		Line   int
 		Column int
 	}
+
+	// Test is a test "name" { ... } block, only compiled by volant test
+	Test struct {
+		Name   Token
+		Block  Block
+		Line   int
+		Column int
+	}
+	Assert struct {
+		Cond   Expression
+		Line   int
+		Column int
+	}
 )
 
 type (
@@ -170,6 +188,15 @@ type (
 		Column int
 	}
 
+	// InterpolatedString is a string literal with expressions in braces,
+	// Texts are the string literals around the expressions, Texts[x] is before Exprs[x] and the last one is after all of them
+	InterpolatedString struct {
+		Texts  []BasicLit
+		Exprs  []Expression
+		Line   int
+		Column int
+	}
+
 	BinaryExpr struct {
 		Left   Expression
 		Op     Token
@@ -305,6 +332,7 @@ type (
 		SuperStructs []Expression
 		Line         int
 		Column       int
+		EndLine      int
 	}
 
 	TupleType struct {
@@ -400,8 +428,11 @@ func (Continue) isStatement()        {}
 func (Defer) isStatement()           {}
 func (Delete) isStatement()          {}
 func (ExportStatement) isStatement() {}
+func (Test) isStatement()            {}
+func (Assert) isStatement()          {}
 
 func (BasicLit) isExpression()            {}
+func (InterpolatedString) isExpression()  {}
 func (BinaryExpr) isExpression()          {}
 func (UnaryExpr) isExpression()           {}
 func (CallExpr) isExpression()            {}
@@ -421,6 +452,7 @@ func (SizeExpr) isExpression()            {}
 func (PointerMemberExpr) isExpression()   {}
 
 func (BasicLit) isStatement()            {}
+func (InterpolatedString) isStatement()  {}
 func (BinaryExpr) isStatement()          {}
 func (UnaryExpr) isStatement()           {}
 func (CallExpr) isStatement()            {}
@@ -535,6 +567,12 @@ func (s Delete) LineM() int {
 func (s ExportStatement) LineM() int {
 	return s.Line
 }
+func (s Test) LineM() int {
+	return s.Line
+}
+func (s Assert) LineM() int {
+	return s.Line
+}
 
 func (s Block) ColumnM() int {
 	return s.Column
@@ -578,7 +616,16 @@ func (s Delete) ColumnM() int {
 func (s ExportStatement) ColumnM() int {
 	return s.Column
 }
+func (s Test) ColumnM() int {
+	return s.Column
+}
+func (s Assert) ColumnM() int {
+	return s.Column
+}
 
+func (e InterpolatedString) LineM() int {
+	return e.Line
+}
 func (e BasicLit) LineM() int {
 	return e.Line
 }
@@ -633,6 +680,9 @@ func (e SizeExpr) LineM() int {
 func (e PointerMemberExpr) LineM() int {
 	return e.Line
 }
+func (e InterpolatedString) ColumnM() int {
+	return e.Column
+}
 func (e BasicLit) ColumnM() int {
 	return e.Column
 }
@@ -825,7 +875,7 @@ var I64Type = Typedef{Name: I64Token, Type: BasicType{Expr: IdentExpr{Value: Tok
 var F32Token = Token{Buff: []byte("f32"), PrimaryType: Identifier}
 var F32Type = Typedef{Name: F32Token, Type: BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("$f32"), PrimaryType: Identifier}}}}
 
-var F64Token = Token{Buff: []byte("i64"), PrimaryType: Identifier}
+var F64Token = Token{Buff: []byte("f64"), PrimaryType: Identifier}
 var F64Type = Typedef{Name: F64Token, Type: BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("$f64"), PrimaryType: Identifier}}}}
 
 var True = IdentExpr{Value: Token{Buff: []byte("true"), PrimaryType: Identifier}}
@@ -837,7 +887,10 @@ var SizeTType = Typedef{Name: I64Token, Type: BasicType{Expr: IdentExpr{Value: T
 var UptrToken = Token{Buff: []byte("uptr"), PrimaryType: Identifier}
 var UptrType = Typedef{Name: I64Token, Type: BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("$uptr"), PrimaryType: Identifier}}}}
 
+var StrToken = Token{Buff: []byte("str"), PrimaryType: Identifier}
+var StrType = Typedef{Name: StrToken, Type: BasicType{Expr: IdentExpr{Value: Token{Buff: []byte("$str"), PrimaryType: Identifier}}}}
+
 var Null = IdentExpr{Value: Token{Buff: []byte("null"), PrimaryType: Identifier}}
 
-var Globals = []string{"u8", "u16", "u32", "u64", "i8", "i16", "i32", "i64", "uptr", "f32", "f64", "void", "bool", "size_t", "true", "false", "null"}
+var Globals = []string{"u8", "u16", "u32", "u64", "i8", "i16", "i32", "i64", "uptr", "f32", "f64", "void", "bool", "size_t", "str", "true", "false", "null"}
 var GlobalTypes = []string{"u8", "u16", "u32", "u64", "i8", "i16", "i32", "i64", "uptr", "f32", "f64", "void", "bool", "size_t"}
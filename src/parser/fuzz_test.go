@@ -0,0 +1,49 @@
+package parser_test
+
+import (
+	"error"
+	"io/ioutil"
+	. "parser"
+	"path/filepath"
+	"testing"
+)
+
+// seed adds the examples and the compiler's fixtures to the corpus of f
+func seed(f *testing.F) {
+	for _, pattern := range []string{"../../examples/*.vo", "../../lib/*.vo", "../compiler/testdata/fixtures/*.vo"} {
+		files, _ := filepath.Glob(pattern)
+		for _, file := range files {
+			if code, err := ioutil.ReadFile(file); err == nil {
+				f.Add(code)
+			}
+		}
+	}
+}
+
+func FuzzLexer(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, code []byte) {
+		lexer := &Lexer{Buffer: code, Line: 1, Column: 1, Path: "fuzz.vo", KeepComments: true}
+
+		error.Catch(func() {
+			for token := lexer.NextToken(); token.PrimaryType != EOF && token.PrimaryType != ErrorToken; token = lexer.NextToken() {
+			}
+		})
+	})
+}
+
+func FuzzParseFile(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, code []byte) {
+		error.Catch(func() {
+			ParseFile(&Lexer{Buffer: code, Line: 1, Column: 1, Path: "fuzz.vo", KeepComments: true})
+		})
+	})
+}
+
+func FuzzReader(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, code []byte) {
+		compareReader(t, "fuzz.vo", code)
+	})
+}
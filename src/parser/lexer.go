@@ -2,26 +2,92 @@ package parser
 
 import (
 	"error"
-	"math"
+	"io"
 	"strconv"
+	"unicode/utf8"
 )
 
+// ChunkSize is the number of bytes read from Lexer.Reader at a time
+var ChunkSize = 4096
+
 //Lexer does stuff
 type Lexer struct {
-	Buffer   []byte // code read from some file or something
-	Position int    // position of char to process next in Buffer
+	Buffer   []byte    // code read from some file or something
+	Position int       // position of char to process next in Buffer
+	Reader   io.Reader // when set, code is read from it in chunks once Buffer runs out, set to nil at eof
 
 	// for error messages and all
 	Line   int // line count of the current char in code
 	Column int // column count of the current char in the current line
 
 	Path string // path of current file
+
+	// trivia, only recorded when KeepComments is set
+	KeepComments bool      // record comments and blank lines instead of discarding them
+	Comments     []Comment // comments read so far
+	BlankLines   []int     // line numbers of the blank lines read so far
+
+	leading []Comment // comments before the next token
+	blank   int       // blank lines before the next token
+
+	// start of the line of the comment being read, bytes from here on are kept in Buffer
+	commentStart int
+	inComment    bool
+}
+
+// Comment is a comment recorded by the lexer, Text includes the delimiters
+type Comment struct {
+	Text       []byte
+	Line       int
+	Column     int
+	Trailing   bool // true when the comment follows code on the same line
+	BlankLines int  // number of blank lines right before the comment
+}
+
+// readToBuffer reads the next chunk from Reader into Buffer, returns the number of bytes read, 0 at eof
+func (lexer *Lexer) readToBuffer() int {
+	if lexer.Reader == nil {
+		return 0 // eof
+	}
+	lexer.discard()
+
+	if cap(lexer.Buffer)-len(lexer.Buffer) < ChunkSize {
+		buffer := make([]byte, len(lexer.Buffer), len(lexer.Buffer)+ChunkSize)
+		copy(buffer, lexer.Buffer)
+		lexer.Buffer = buffer
+	}
+
+	for {
+		end := len(lexer.Buffer)
+		n, err := lexer.Reader.Read(lexer.Buffer[end : end+ChunkSize])
+		lexer.Buffer = lexer.Buffer[:end+n]
+
+		if err == io.EOF {
+			lexer.Reader = nil
+			return n
+		} else if err != nil {
+			lexer.Reader = nil
+			error.NewFileError(lexer.Path, "error reading source: "+err.Error(), lexer.Line, lexer.Column)
+		} else if n > 0 {
+			return n
+		}
+	}
 }
 
-func (lexer *Lexer) readToBuffer() byte {
-	// read few bytes from a file or command line or whatever
-	// lexer.Buffer = append(lexer.Buffer, 'a') // just for now
-	return 0 // eof
+// discard drops the bytes before the current line from Buffer, they are never looked at again
+// the lines of a comment being read are kept as comments are recorded as a whole
+func (lexer *Lexer) discard() {
+	start := lexer.Position - lexer.Column + 1
+	if lexer.inComment && lexer.commentStart < start {
+		start = lexer.commentStart
+	}
+	if start <= 0 {
+		return
+	}
+
+	lexer.Buffer = append(lexer.Buffer[:0], lexer.Buffer[start:]...)
+	lexer.Position -= start
+	lexer.commentStart -= start
 }
 
 func (lexer *Lexer) nextChar() (byte, bool) {
@@ -36,7 +102,7 @@ func (lexer *Lexer) nextChar() (byte, bool) {
 // get the next character without incrementing the position counter of lexer
 func (lexer *Lexer) peek() (byte, bool) {
 	// if no more characters to read in the Buffer
-	if lexer.Position == len(lexer.Buffer) {
+	for lexer.Position >= len(lexer.Buffer) {
 		// read few bytes from the source file and push it to Buffer
 		// return 0 if eof reached
 		if lexer.readToBuffer() == 0 {
@@ -48,17 +114,37 @@ func (lexer *Lexer) peek() (byte, bool) {
 	return lexer.Buffer[lexer.Position], true
 }
 
+// peekRune decodes the UTF-8 character at the position counter without incrementing it, size is 0 at eof
+func (lexer *Lexer) peekRune() (rune, int) {
+	if _, ok := lexer.peek(); !ok {
+		return utf8.RuneError, 0
+	}
+	for !utf8.FullRune(lexer.Buffer[lexer.Position:]) && lexer.readToBuffer() != 0 {
+	}
+	return utf8.DecodeRune(lexer.Buffer[lexer.Position:])
+}
+
+// peekSecond returns the character after the next one without incrementing the position counter
+func (lexer *Lexer) peekSecond() (byte, bool) {
+	for lexer.Position+1 >= len(lexer.Buffer) {
+		if lexer.readToBuffer() == 0 {
+			return 0, false
+		}
+	}
+	return lexer.Buffer[lexer.Position+1], true
+}
+
 // increment the position counter of Lexer by 1
 func (lexer *Lexer) eatLastByte() {
 	lexer.Position++
 	lexer.Column++
 }
 
-// increment the line count and zero the column count when a newline is reached
+// increment the line count and reset the column count when a newline is reached
 func (lexer *Lexer) shiftLine() {
 	lexer.Position++
 	lexer.Line++
-	lexer.Column = 0
+	lexer.Column = 1
 }
 
 func (lexer *Lexer) skipSpaces() {
@@ -75,7 +161,7 @@ func (lexer *Lexer) skipSpaces() {
 }
 
 func (lexer *Lexer) skipUntilNewline() {
-	for next, ok := lexer.peek(); ok && next != '\n'; next, _ = lexer.peek() {
+	for next, ok := lexer.peek(); ok && next != '\n'; next, ok = lexer.peek() {
 		lexer.eatLastByte()
 	}
 }
@@ -119,42 +205,96 @@ func (lexer *Lexer) skipComments() {
 	}
 }
 
+// trailing checks if there is anything other than spaces between the start of the line and pos
+func (lexer *Lexer) trailing(pos int) bool {
+	for i := pos - 1; i >= 0 && lexer.Buffer[i] != '\n'; i-- {
+		if !IsSpace(lexer.Buffer[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// blankLines records the blank lines between line and the current line
+func (lexer *Lexer) blankLines(line int) {
+	for l := line + 1; l < lexer.Line; l++ {
+		lexer.BlankLines = append(lexer.BlankLines, l)
+		lexer.blank++
+	}
+}
+
 func (lexer *Lexer) PrepNext() {
 	for {
 		if next, _ := lexer.peek(); IsSpace(next) {
+			line := lexer.Line
 			lexer.skipSpaces() // skiping spaces/tabs/newlines
+
+			if lexer.KeepComments {
+				lexer.blankLines(line)
+			}
 		}
+
+		line, column := lexer.Line, lexer.Column
+
 		if next, _ := lexer.peek(); next == byte('/') {
 			lexer.eatLastByte()
 		} else {
 			break
 		}
+		lexer.commentStart, lexer.inComment = lexer.Position-column, true
+
 		if next, _ := lexer.peek(); next == '/' {
 			lexer.skipUntilNewline()
 		} else if next == '*' {
+			lexer.eatLastByte()
 			lexer.multilineComment()
 		} else {
 			lexer.Position--
 			lexer.Column--
+			lexer.inComment = false
 			break
 		}
+		lexer.inComment = false
+
+		start := lexer.commentStart + column - 1
+		if lexer.KeepComments {
+			// Buffer is discarded as Reader is read, so the text is copied
+			text := append([]byte{}, lexer.Buffer[start:lexer.Position]...)
+			comment := Comment{Text: text, Line: line, Column: column, Trailing: lexer.trailing(start), BlankLines: lexer.blank}
+			lexer.Comments = append(lexer.Comments, comment)
+			lexer.leading = append(lexer.leading, comment)
+			lexer.blank = 0
+		}
 	}
 }
 
-// NextToken returns next token
+// NextToken returns next token, with the comments and blank lines before it if KeepComments is set
 func (lexer *Lexer) NextToken() Token {
 	lexer.PrepNext()
+	token := lexer.lexToken()
+
+	if lexer.KeepComments {
+		token.Comments, token.BlankLines = lexer.leading, lexer.blank
+		lexer.leading, lexer.blank = nil, 0
+	}
+	return token
+}
+
+func (lexer *Lexer) lexToken() Token {
 	character, ok := lexer.peek()
 	if !ok {
 		// return eof token (tell the parser to stop further parsing)
 		return Token{PrimaryType: EOF, SecondaryType: SecondaryNullType, Buff: nil, Line: lexer.Line, Column: lexer.Column}
-	} else if IsIdentifierBegining(character) {
+	} else if r, _ := lexer.peekRune(); IsIdentifierStart(r) {
 		return lexer.lexWord() // identifier or keyword or macros
 	} else if IsNumDec(character) {
 		return lexer.lexNumber() // number, hex starting with 0x, octals with 0o, decimals either directly or with 0d, and binary with 0b
 	} else if IsStringDelimiter(character) { // string delimiter is "
 		lexer.eatLastByte()
 		return lexer.lexString()
+	} else if IsRawStringDelimiter(character) { // raw string delimiter is `
+		lexer.eatLastByte()
+		return lexer.lexRawString()
 	} else if IsCharDelimiter(character) { // char delimiter is '
 		lexer.eatLastByte()
 		return lexer.lexChar() // just a single byte
@@ -184,7 +324,6 @@ func (lexer *Lexer) lexNumber() Token {
 		if next, _ := lexer.peek(); next == 'd' { // decimal
 			radix = DecimalRadix
 			lexer.eatLastByte()
-			lexer.eatLastByte()
 
 			if n3, _ := lexer.peek(); n3 == '0' || n3 == '_' {
 				lexer.eatLastByte()
@@ -269,13 +408,11 @@ func (lexer *Lexer) lexNumber() Token {
 
 func (lexer *Lexer) lexChar() Token {
 
-	// stores the utf32 code of char
+	// stores the code point of char
 	num := 0
 
-	var encoding SecondaryTokenType
-
 	line := lexer.Line
-	column := lexer.Column
+	column := lexer.Column - 1 // the opening ' is already eaten
 
 	character, ok := lexer.peek()
 
@@ -287,14 +424,13 @@ func (lexer *Lexer) lexChar() Token {
 		return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 	}
 
-	lexer.eatLastByte()
-
 	if character == '\\' {
+		lexer.eatLastByte()
 		next, ok := lexer.peek()
 
 		if !ok { // Error: Expected char, got eof
 			return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
-		} else if character == '\n' {
+		} else if next == '\n' {
 			// Error: Expected char, got end of line
 			return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 		}
@@ -308,83 +444,47 @@ func (lexer *Lexer) lexChar() Token {
 			num = '\n'
 		case 'r':
 			num = '\r'
+		case 'v':
+			num = '\v'
+		case 'f':
+			num = '\f'
+		case '0':
+			num = 0
 		case '\'':
 			num = '\''
 		case '\\':
 			num = '\\'
-		case 'u': // 2 byte char
-			for i := 0; i < 4; i++ {
-				chr, ok := lexer.peek()
-
-				if !ok { // Error: Expected escape sequence, got eof
-					error.New("exprected escape sequence, got eof.", line, column)
-				} else if IsNumHex(chr) {
-					num += HexToInt(chr) * Pow(16, (3-i))
-					lexer.eatLastByte()
-				} else { // Error: Invalid character in escape sequence, expected (0-9|A-F|a-f)
-					error.New("invalid character in escape sequence.", line, column)
-				}
-			}
-
-			encoding = Byte2Char
-		case 'U': // 4 byte char
-			for i := 0; i < 8; i++ {
-				chr, ok := lexer.peek()
-
-				if !ok { // Error: Expected escape sequence, got eof
-					error.New("exprected escape sequence, got eof.", line, column)
-				} else if IsNumHex(chr) {
-					num += HexToInt(chr) * Pow(16, (7-i))
-					lexer.eatLastByte()
-				} else { // Error: Invalid character in escape sequence, expected (0-9|A-F|a-f)
-					error.New("invalid character in escape sequence.", line, column)
-				}
-			}
-			encoding = Byte4Char
+		case 'u': // 4 hex digits
+			num = lexer.lexCodePoint(4, line, column)
+		case 'U': // 8 hex digits
+			num = lexer.lexCodePoint(8, line, column)
 		default:
 			// idk we can prob throw an error here saying that its an invalid escape sequence?
 			num = int(next)
 		}
-		if nextChar, _ := lexer.peek(); nextChar == '\'' {
-			lexer.eatLastByte() // increament the positon as `nextChar` was `'` as expected
-			return Token{PrimaryType: CharLiteral, SecondaryType: encoding, Buff: []byte(strconv.Itoa(num)), Line: line, Column: column}
-		}
-		error.New("exprected ', got eof.", line, column)
-	} else if character>>7 == 0 { // 1 byte char
-		encoding = Byte1Char
-	} else if character>>5 == 0b110 { // 2 byte char
-		encoding = Byte2Char
-	} else if character>>4 == 0b1110 { // 3 byte char
-		encoding = Byte3Char
-	} else if character>>3 == 0b11110 { // 4 byte char
-		encoding = Byte4Char
 	} else {
-		// Error: Unsupported/Invalid character encoding
-		return Token{PrimaryType: ErrorToken, SecondaryType: UnknownChar, Buff: nil, Line: lexer.Line, Column: lexer.Column}
-	}
-
-	num = int(character)
-	len := int(encoding) - int(Byte1Char) + 1
-
-	for i := 1; i < len; i++ {
-		if chr, ok := lexer.peek(); !ok {
-			// Error: expected char, got eof
-			return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
-		} else if chr>>6 == 0b10 {
+		// utf8 decoding rejects overlong encodings, surrogates and code points above U+10FFFF
+		r, size := lexer.peekRune()
+		if r == utf8.RuneError && size == 1 {
+			error.New("invalid UTF-8 encoding in char literal.", lexer.Line, lexer.Column)
+		}
+		for i := 0; i < size; i++ {
 			lexer.eatLastByte()
-			num += int(math.Pow(float64(chr), 16*float64(i)))
-		} else {
-			// Error: Invalid char
-			return Token{PrimaryType: ErrorToken, SecondaryType: SecondaryNullType, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 		}
+		num = int(r)
 	}
 
+	// the encoding is the number of bytes the char takes in UTF-8
+	encoding := Byte1Char + SecondaryTokenType(utf8.RuneLen(rune(num))-1)
+
 	if nextChar, ok := lexer.peek(); !ok {
 		// Error: Expected char demlimiter, got eof
 		return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 	} else if nextChar == '\'' {
 		lexer.eatLastByte() // increament the positon as `nextChar` was `'` as expected
-		return Token{PrimaryType: CharLiteral, SecondaryType: encoding, Buff: []byte(strconv.Itoa(num)), Line: line, Column: column}
+		// the literal is on the current line, which stays in Buffer
+		raw := append([]byte{}, lexer.Buffer[lexer.Position-(lexer.Column-column):lexer.Position]...)
+		return Token{PrimaryType: CharLiteral, SecondaryType: encoding, Buff: []byte(strconv.Itoa(num)), Raw: raw, Line: line, Column: column}
 	}
 
 	// Error: Invalid character {nedxtChar} at {Lexer.line}:{Lexer.column}. Expected `'`.
@@ -392,12 +492,35 @@ func (lexer *Lexer) lexChar() Token {
 	return Token{PrimaryType: ErrorToken, SecondaryType: SecondaryNullType, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 }
 
+// lexCodePoint reads the hex digits of a \u or \U escape sequence, the code point must be a valid unicode scalar value
+func (lexer *Lexer) lexCodePoint(digits int, line int, column int) int {
+	num := 0
+
+	for i := 0; i < digits; i++ {
+		chr, ok := lexer.peek()
+
+		if !ok { // Error: Expected escape sequence, got eof
+			error.New("exprected escape sequence, got eof.", line, column)
+		} else if IsNumHex(chr) {
+			num = num*16 + HexToInt(chr)
+			lexer.eatLastByte()
+		} else { // Error: Invalid character in escape sequence, expected (0-9|A-F|a-f)
+			error.New("invalid character in escape sequence.", line, column)
+		}
+	}
+
+	if !utf8.ValidRune(rune(num)) {
+		error.New("invalid code point in escape sequence.", line, column)
+	}
+	return num
+}
+
 func (lexer *Lexer) lexString() Token {
 	str := []byte{'"'}
 
 	line := lexer.Line
-	column := lexer.Column
-	size := 1
+	column := lexer.Column - 1 // the opening " is already eaten
+	var kind SecondaryTokenType = SecondaryNullType
 
 	for character, ok := lexer.peek(); !IsStringDelimiter(character); character, ok = lexer.peek() {
 
@@ -407,6 +530,11 @@ func (lexer *Lexer) lexString() Token {
 		} else if character == '\n' {
 			// Error: Expected end of string literal, got end of line
 			error.New("expected \", got end of line.", lexer.Line, lexer.Column)
+		} else if next, _ := lexer.peekSecond(); character == '{' && StartsInterpolation(next) {
+			// the expression is parsed by the parser, the lexer only finds where it ends
+			str = append(str, lexer.lexInterpolation()...)
+			kind = Interpolated
+			continue
 		}
 
 		str = append(str, character)
@@ -418,27 +546,90 @@ func (lexer *Lexer) lexString() Token {
 			if !ok {
 				// Error: Expected end of string literal, got eof
 				return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
-			} else if character == '\n' {
+			} else if next == '\n' {
 				// Error: Expected end of string literal, got end of line
 				return Token{PrimaryType: ErrorToken, SecondaryType: UnexpectedEOF, Buff: nil, Line: lexer.Line, Column: lexer.Column}
 			}
 
-			if chr, _ := lexer.peek(); chr == 'u' {
-				size += 3
-			} else if chr == 'U' {
-				size += 7
-			}
-
 			str = append(str, next)
 			character = next
 			lexer.eatLastByte()
 		}
-		size++
 	}
 
 	lexer.eatLastByte() // eat '"'
+	// the size counts the bytes the escape sequences stand for, plus one like the size of a raw string
+	size := len(Unescape(str[1:])) + 1
 	str = append(str, '"')
-	return Token{PrimaryType: StringLiteral, SecondaryType: SecondaryNullType, Flags: size, Buff: str, Line: line, Column: column}
+	return Token{PrimaryType: StringLiteral, SecondaryType: kind, Flags: size, Buff: str, Line: line, Column: column}
+}
+
+// lexInterpolation reads an expression in braces in a string literal, up to the matching brace
+// strings and chars in the expression are skipped, so that the braces in them are not counted
+func (lexer *Lexer) lexInterpolation() []byte {
+	expr := []byte{}
+	depth := 0
+	var quote byte
+
+	for {
+		character, ok := lexer.peek()
+
+		if !ok {
+			error.New("expected }, got eof.", lexer.Line, lexer.Column)
+		} else if character == '\n' {
+			error.New("expected }, got end of line.", lexer.Line, lexer.Column)
+		}
+
+		expr = append(expr, character)
+		lexer.eatLastByte()
+
+		switch {
+		case quote != 0 && character == '\\':
+			if next, ok := lexer.peek(); ok && next != '\n' {
+				expr = append(expr, next)
+				lexer.eatLastByte()
+			}
+		case quote != 0:
+			if character == quote {
+				quote = 0
+			}
+		case character == '"' || character == '\'':
+			quote = character
+		case character == '{':
+			depth++
+		case character == '}':
+			depth--
+		}
+
+		if depth == 0 {
+			return expr
+		}
+	}
+}
+
+// lexRawString reads a string literal in backticks, it can span lines and has no escape sequences or interpolation
+func (lexer *Lexer) lexRawString() Token {
+	str := []byte{'`'}
+
+	line := lexer.Line
+	column := lexer.Column - 1 // the opening ` is already eaten
+
+	for character, ok := lexer.peek(); !IsRawStringDelimiter(character); character, ok = lexer.peek() {
+		if !ok {
+			error.New("expected `, got eof.", lexer.Line, lexer.Column)
+		}
+
+		str = append(str, character)
+		if character == '\n' {
+			lexer.shiftLine()
+		} else {
+			lexer.eatLastByte()
+		}
+	}
+
+	lexer.eatLastByte() // eat '`'
+	str = append(str, '`')
+	return Token{PrimaryType: StringLiteral, SecondaryType: RawString, Flags: len(str) - 1, Buff: str, Line: line, Column: column}
 }
 
 func (lexer *Lexer) lexWord() Token {
@@ -447,12 +638,12 @@ func (lexer *Lexer) lexWord() Token {
 	column := lexer.Column
 	line := lexer.Line
 
-	character, _ := lexer.nextChar()
-	word = append(word, character)
-
-	for w, _ := lexer.peek(); IsIdentifierPart(w); w, _ = lexer.peek() {
-		word = append(word, w)
-		lexer.eatLastByte()
+	// the first character is already checked by lexToken
+	for r, size := lexer.peekRune(); size > 0 && (len(word) == 0 || IsIdentifierRune(r)); r, size = lexer.peekRune() {
+		word = append(word, lexer.Buffer[lexer.Position:lexer.Position+size]...)
+		for i := 0; i < size; i++ {
+			lexer.eatLastByte()
+		}
 	}
 
 	return Token{PrimaryType: GetWordType(string(word)), SecondaryType: SecondaryNullType, Buff: word, Line: line, Column: column}
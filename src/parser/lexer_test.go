@@ -0,0 +1,220 @@
+package parser_test
+
+import (
+	"bytes"
+	"error"
+	"io/ioutil"
+	. "parser"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/iotest"
+)
+
+// lex returns the tokens, comments and blank lines of lexer, and the error that stopped it
+func lex(lexer *Lexer) ([]Token, []Comment, []int, *error.Error) {
+	tokens := []Token{}
+	err := error.Catch(func() {
+		for token := lexer.NextToken(); token.PrimaryType != EOF && token.PrimaryType != ErrorToken; token = lexer.NextToken() {
+			tokens = append(tokens, token)
+		}
+	})
+	return tokens, lexer.Comments, lexer.BlankLines, err
+}
+
+// compareReader checks that lexing code from a Reader gives what lexing it from Buffer gives
+func compareReader(t *testing.T, name string, code []byte) {
+	t.Helper()
+
+	wantTokens, wantComments, wantBlank, wantErr := lex(&Lexer{Buffer: code, Line: 1, Column: 1, Path: name, KeepComments: true})
+	gotTokens, gotComments, gotBlank, gotErr := lex(&Lexer{Reader: iotest.OneByteReader(bytes.NewReader(code)), Line: 1, Column: 1, Path: name, KeepComments: true})
+
+	if !reflect.DeepEqual(gotTokens, wantTokens) {
+		t.Errorf("%s: tokens read from a Reader differ", name)
+	}
+	if !reflect.DeepEqual(gotComments, wantComments) || !reflect.DeepEqual(gotBlank, wantBlank) {
+		t.Errorf("%s: comments or blank lines read from a Reader differ", name)
+	}
+	if !reflect.DeepEqual(gotErr, wantErr) {
+		t.Errorf("%s: got error %v from a Reader, want %v", name, gotErr, wantErr)
+	}
+}
+
+func TestReader(t *testing.T) {
+	chunk := ChunkSize
+	ChunkSize = 3
+	defer func() {
+		ChunkSize = chunk
+	}()
+
+	files, _ := filepath.Glob("../../examples/*.vo")
+	lib, _ := filepath.Glob("../../lib/*.vo")
+
+	for _, file := range append(files, lib...) {
+		code, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareReader(t, file, code)
+	}
+
+	compareReader(t, "comments.vo", []byte("a /* one\n two */ b // three\n\n\n/* four */\nc / d /"))
+	compareReader(t, "unterminated.vo", []byte("a := \"b\n"))
+}
+
+func TestReaderDiscards(t *testing.T) {
+	code := bytes.Repeat([]byte("x := 1;\n"), 10000)
+	lexer := &Lexer{Reader: bytes.NewReader(code), Line: 1, Column: 1, Path: "big.vo"}
+
+	tokens := 0
+	for token := lexer.NextToken(); token.PrimaryType != EOF; token = lexer.NextToken() {
+		tokens++
+	}
+
+	if tokens != 50000 || lexer.Line != 10001 {
+		t.Errorf("got %d tokens and line %d, want 50000 and 10001", tokens, lexer.Line)
+	}
+	if cap(lexer.Buffer) > 2*ChunkSize {
+		t.Errorf("Buffer grew to %d bytes, want at most %d", cap(lexer.Buffer), 2*ChunkSize)
+	}
+}
+
+// token lexes the first token of code
+func token(code string) (Token, *error.Error) {
+	var token Token
+	err := error.Catch(func() {
+		token = (&Lexer{Buffer: []byte(code), Line: 1, Column: 1, Path: "char.vo"}).NextToken()
+	})
+	return token, err
+}
+
+func TestChars(t *testing.T) {
+	valid := map[string]struct {
+		code     int
+		encoding SecondaryTokenType
+	}{
+		`'a'`:            {'a', Byte1Char},
+		`'\n'`:           {'\n', Byte1Char},
+		`'\''`:           {'\'', Byte1Char},
+		`'\v'`:           {'\v', Byte1Char},
+		`'\f'`:           {'\f', Byte1Char},
+		`'\0'`:           {0, Byte1Char},
+		`'é'`:            {0xE9, Byte2Char},
+		`'€'`:            {0x20AC, Byte3Char},
+		`'😀'`:            {0x1F600, Byte4Char},
+		`'\u00e9'`:       {0xE9, Byte2Char},
+		`'\uFFFF'`:       {0xFFFF, Byte3Char},
+		`'\U0001F600'`:   {0x1F600, Byte4Char},
+		`'\U0010ffff'`:   {0x10FFFF, Byte4Char},
+		"'\xef\xbf\xbd'": {0xFFFD, Byte3Char}, // U+FFFD itself is valid
+	}
+
+	for src, want := range valid {
+		tok, err := token(src)
+		if err != nil || tok.PrimaryType != CharLiteral {
+			t.Errorf("%s: got %v %v, want a char literal", src, tok, err)
+			continue
+		}
+		if string(tok.Buff) != strconv.Itoa(want.code) || tok.SecondaryType != want.encoding {
+			t.Errorf("%s: got %s %s, want %d %s", src, tok.Buff, SecondaryTypes[tok.SecondaryType], want.code, SecondaryTypes[want.encoding])
+		}
+	}
+
+	invalid := map[string]string{
+		"'\xc0\x80'":         "invalid UTF-8 encoding in char literal.", // overlong NUL
+		"'\xe0\x80\xaf'":     "invalid UTF-8 encoding in char literal.", // overlong /
+		"'\xed\xa0\x80'":     "invalid UTF-8 encoding in char literal.", // surrogate U+D800
+		"'\xf4\x90\x80\x80'": "invalid UTF-8 encoding in char literal.", // above U+10FFFF
+		"'\xe2\x82'":         "invalid UTF-8 encoding in char literal.", // truncated
+		"'\x80'":             "invalid UTF-8 encoding in char literal.", // continuation byte
+		`'\uD800'`:           "invalid code point in escape sequence.",
+		`'\U00110000'`:       "invalid code point in escape sequence.",
+		`'\u12G4'`:           "invalid character in escape sequence.",
+	}
+
+	for src, want := range invalid {
+		if tok, err := token(src); err == nil || err.Message != want {
+			t.Errorf("%q: got %v %v, want error %q", src, tok, err, want)
+		}
+	}
+
+	if tok, _ := token("'ab'"); tok.PrimaryType != ErrorToken {
+		t.Errorf("'ab': got %v, want an error token", tok)
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	code := "café := 変数 + x̃1 - _a$ * Ωmega2 1b é"
+	want := []struct {
+		buff   string
+		column int
+	}{{"café", 1}, {":", 7}, {"=", 8}, {"変数", 10}, {"+", 17}, {"x̃1", 19}, {"-", 24}, {"_a$", 26}, {"*", 30}, {"Ωmega2", 32}, {"1", 40}, {"b", 41}, {"é", 43}}
+
+	lexer := &Lexer{Buffer: []byte(code), Line: 1, Column: 1, Path: "ident.vo"}
+	for _, w := range want {
+		tok := lexer.NextToken()
+		if string(tok.Buff) != w.buff || tok.Column != w.column {
+			t.Errorf("got %q at column %d, want %q at column %d", tok.Buff, tok.Column, w.buff, w.column)
+		}
+	}
+	if tok := lexer.NextToken(); tok.PrimaryType != EOF {
+		t.Errorf("got %q, want eof", tok.Buff)
+	}
+
+	// letters are identifiers, other characters are not
+	for _, src := range []string{"€", "​", "\xff"} {
+		if _, err := token(src); err == nil || err.Message != "Unknown character." {
+			t.Errorf("%q: got error %v, want Unknown character.", src, err)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	valid := map[string]struct {
+		buff string
+		kind SecondaryTokenType
+		size int // -1 for interpolated strings, whose size is only known once they are built
+	}{
+		`"a\n"`:            {`"a\n"`, SecondaryNullType, 3},
+		`"a {b} c"`:        {`"a {b} c"`, Interpolated, -1},
+		`"{f("}", '{')}"`:  {`"{f("}", '{')}"`, Interpolated, -1},
+		`"{{a: 1}.a}"`:     {`"{{a: 1}.a}"`, Interpolated, -1},
+		`"\{a\}"`:          {`"\{a\}"`, SecondaryNullType, 4},
+		`"{}"`:             {`"{}"`, SecondaryNullType, 3},
+		`"{ %d }"`:         {`"{ %d }"`, SecondaryNullType, 7},
+		`"{%s} {b}"`:       {`"{%s} {b}"`, Interpolated, -1},
+		"`a\\n\"{b}\"\nc`": {"`a\\n\"{b}\"\nc`", RawString, 11},
+		"``":               {"``", RawString, 1},
+	}
+
+	for src, want := range valid {
+		tok, err := token(src)
+		if err != nil || tok.PrimaryType != StringLiteral {
+			t.Errorf("%s: got %v %v, want a string literal", src, tok, err)
+			continue
+		}
+		if string(tok.Buff) != want.buff || tok.SecondaryType != want.kind || want.size >= 0 && tok.Flags != want.size {
+			t.Errorf("%s: got %s %s %d, want %s %s %d", src, tok.Buff, SecondaryTypes[tok.SecondaryType], tok.Flags, want.buff, SecondaryTypes[want.kind], want.size)
+		}
+	}
+
+	invalid := map[string]string{
+		`"a {b"`:      "expected }, got eof.",
+		"\"a {b\n}\"": "expected }, got end of line.",
+		"`a":          "expected `, got eof.",
+	}
+
+	for src, want := range invalid {
+		if tok, err := token(src); err == nil || err.Message != want {
+			t.Errorf("%q: got %v %v, want error %q", src, tok, err, want)
+		}
+	}
+
+	// raw strings count the lines they span
+	lexer := &Lexer{Buffer: []byte("`a\nb\n` c"), Line: 1, Column: 1, Path: "raw.vo"}
+	lexer.NextToken()
+	if tok := lexer.NextToken(); tok.Line != 3 || tok.Column != 3 {
+		t.Errorf("got c at %d:%d, want 3:3", tok.Line, tok.Column)
+	}
+}
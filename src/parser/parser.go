@@ -19,20 +19,62 @@ func ParseFile(inputStream *Lexer) File {
 
 	file := File{}
 
-	for parser.ReadToken().PrimaryType != EOF {
-		file.Statements = append(file.Statements, parser.parseGlobalStatement())
+	for token := parser.ReadToken(); token.PrimaryType != EOF; token = parser.ReadToken() {
+		file.Statements = append(file.Statements, withDoc(parser.parseGlobalStatement(), DocComments(token)))
 	}
 
+	file.Comments = parser.Lexer.Comments
+	file.BlankLines = parser.Lexer.BlankLines
 	return file
 }
 
+// DocComments returns the comments directly above token, with no blank lines between them and the token
+func DocComments(token Token) []Comment {
+	if token.BlankLines > 0 {
+		return nil
+	}
+
+	start := len(token.Comments)
+	for start > 0 && !token.Comments[start-1].Trailing {
+		start--
+		if token.Comments[start].BlankLines > 0 {
+			break
+		}
+	}
+
+	if start == len(token.Comments) {
+		return nil
+	}
+	return token.Comments[start:]
+}
+
+// withDoc attaches doc to declarations and typedefs
+func withDoc(stmt Statement, doc []Comment) Statement {
+	switch stmt.(type) {
+	case Declaration:
+		dec := stmt.(Declaration)
+		dec.Doc = doc
+		return dec
+	case Typedef:
+		typedef := stmt.(Typedef)
+		typedef.Doc = doc
+		return typedef
+	case ExportStatement:
+		export := stmt.(ExportStatement)
+		export.Stmt = withDoc(export.Stmt, doc)
+		return export
+	}
+	return stmt
+}
+
+// pos returns the position of the next token
 func (parser *Parser) pos() (int, int) {
-	parser.Lexer.PrepNext()
-	return parser.Lexer.Line, parser.Lexer.Column
+	token := parser.ReadToken()
+	return token.Line, token.Column
 }
 
 func (parser *Parser) error(message string, line, column int) {
-	error.New(parser.Lexer.Path+": "+message, line, column)
+	error.NewFileError(parser.Lexer.Path, message, line, column)
 }
 
 func (parser *Parser) ReadToken() Token {
@@ -42,6 +84,14 @@ func (parser *Parser) ReadToken() Token {
 	return parser.tokens[parser.position]
 }
 
+// peekToken returns the token after the next one
+func (parser *Parser) peekToken() Token {
+	parser.position++
+	token := parser.ReadToken()
+	parser.position--
+	return token
+}
+
 func (parser *Parser) eatLastToken() {
 	parser.position++
 }
@@ -98,14 +148,14 @@ func (parser *Parser) parseGlobalStatementNoSemicolon() Statement {
 		statement = parser.parseTypedef()
 	case ExportKeyword:
 		parser.eatLastToken()
-		statement = ExportStatement{Stmt: parser.parseGlobalStatementNoSemicolon()}
+		statement = ExportStatement{Stmt: parser.parseGlobalStatementNoSemicolon(), Line: token.Line, Column: token.Column}
 	case FunctionKeyword:
 		parser.eatLastToken()
 		statement = parser.parseFunctionDec()
 	case Identifier:
 		statement = parser.parseGlobalDeclaration()
 	default:
-		// Error: Invalid token {token}
+		parser.error("expected a declaration, got '"+token.Serialize()+"'.", token.Line, token.Column)
 	}
 	return statement
 }
@@ -134,17 +184,20 @@ func (parser *Parser) parseGlobalStatement() Statement {
 		return parser.parseTypedef()
 	case ExportKeyword:
 		parser.eatLastToken()
-		return ExportStatement{Stmt: parser.parseGlobalStatement()}
+		return ExportStatement{Stmt: parser.parseGlobalStatement(), Line: token.Line, Column: token.Column}
 	case FunctionKeyword:
 		parser.eatLastToken()
 		return parser.parseFunctionDec()
 	case Identifier:
+		if parser.isTest() {
+			return parser.parseTest()
+		}
 		statement = parser.parseGlobalDeclaration()
 	case SemiColon:
 		parser.eatLastToken()
 		return NullStatement{}
 	default:
-		// Error: Invalid token {token}
+		parser.error("expected a declaration, got '"+token.Serialize()+"'.", token.Line, token.Column)
 	}
 	parser.expect(SemiColon, SecondaryNullType)
 	parser.eatLastToken()
@@ -256,6 +309,9 @@ func (parser *Parser) parseStatement() Statement {
 	case DeleteKeyword:
 		parser.eatLastToken()
 		st = Delete{Exprs: parser.parseExpressionArray(), Line: line, Column: column}
+	case AssertKeyword:
+		parser.eatLastToken()
+		st = Assert{Cond: parser.parseExpression(), Line: line, Column: column}
 	case SemiColon:
 		parser.eatLastToken()
 		return NullStatement{}
@@ -432,20 +488,23 @@ func (parser *Parser) parseStructType() StructType {
 	parser.eatLastToken()
 
 	for {
-		if parser.ReadToken().SecondaryType == DotDot {
+		if parser.ReadToken().PrimaryType == RightCurlyBrace {
+			strct.EndLine = parser.ReadToken().Line
 			parser.eatLastToken()
-			strct.SuperStructs = append(strct.SuperStructs, parser.parseExpression())
-		} else {
-			strct.Props = append(strct.Props, parser.parseDeclaration())
+			break
 		}
 
-		if parser.ReadToken().PrimaryType == SemiColon {
+		if token := parser.ReadToken(); token.SecondaryType == DotDot {
 			parser.eatLastToken()
+			strct.SuperStructs = append(strct.SuperStructs, parser.parseExpression())
+		} else {
+			prop := parser.parseDeclaration()
+			prop.Doc = DocComments(token)
+			strct.Props = append(strct.Props, prop)
 		}
 
-		if parser.ReadToken().PrimaryType == RightCurlyBrace {
+		if parser.ReadToken().PrimaryType == SemiColon {
 			parser.eatLastToken()
-			break
 		}
 	}
 
@@ -556,6 +615,38 @@ func (parser *Parser) parseExpressionArray() []Expression {
 	return exprs
 }
 
+// parseCArguments parses the arguments of a call to a C function, a string literal passed as an argument is given
+// to C as it is written, so the braces in format strings are not interpolated
+func (parser *Parser) parseCArguments() []Expression {
+	exprs := []Expression{parser.parseCArgument()}
+
+	for token := parser.ReadToken(); token.PrimaryType == Comma; token = parser.ReadToken() {
+		parser.eatLastToken()
+		exprs = append(exprs, parser.parseCArgument())
+	}
+	return exprs
+}
+
+func (parser *Parser) parseCArgument() Expression {
+	token := parser.ReadToken()
+	if next := parser.peekToken(); token.PrimaryType == StringLiteral && token.SecondaryType == Interpolated && (next.PrimaryType == Comma || next.PrimaryType == RightParen) {
+		parser.eatLastToken()
+		token.SecondaryType = SecondaryNullType
+		return BasicLit{Value: token, Line: token.Line, Column: token.Column}
+	}
+	return parser.parseExpression()
+}
+
+// isCName reports whether expr names something of C, like $printf
+func isCName(expr Expression) bool {
+	switch expr.(type) {
+	case IdentExpr:
+		name := expr.(IdentExpr).Value.Buff
+		return len(name) > 1 && name[0] == '$'
+	}
+	return false
+}
+
 // var1, var2, ...varn :[type1, type2, ...typen][= val1, val2, ...valn]
 func (parser *Parser) parseDeclaration() Declaration {
 	if token := parser.ReadToken(); token.PrimaryType == FunctionKeyword {
@@ -878,16 +969,45 @@ func (parser *Parser) parseBlock() Block {
 	parser.eatLastToken()
 
 	for token := parser.ReadToken(); token.PrimaryType != RightCurlyBrace; token = parser.ReadToken() {
-		block.Statements = append(block.Statements, parser.parseStatement())
+		block.Statements = append(block.Statements, withDoc(parser.parseStatement(), DocComments(token)))
 	}
 
+	block.EndLine = parser.ReadToken().Line
 	parser.eatLastToken()
 	return block
 }
 
+// isTest reports whether the next tokens start a test block, test is only a keyword when the name of the test follows it
+func (parser *Parser) isTest() bool {
+	if string(parser.ReadToken().Buff) != "test" {
+		return false
+	}
+
+	parser.fork(0)
+	parser.eatLastToken()
+	next := parser.ReadToken()
+	parser.moveToFork(0)
+
+	return next.PrimaryType == StringLiteral
+}
+
+func (parser *Parser) parseTest() Test {
+	line, column := parser.pos()
+	parser.eatLastToken()
+
+	name := parser.expect(StringLiteral, SecondaryNullType)
+	parser.eatLastToken()
+
+	return Test{Name: name, Block: parser.parseBlock(), Line: line, Column: column}
+}
+
 func (parser *Parser) parseReturn() Return {
 	line, column := parser.pos()
 	parser.eatLastToken()
+
+	if parser.ReadToken().PrimaryType == SemiColon {
+		return Return{Values: []Expression{}, Line: line, Column: column}
+	}
 	return Return{Values: parser.parseExpressionArray(), Line: line, Column: column}
 }
 
@@ -1177,7 +1297,11 @@ func (parser *Parser) parseExpr(state int) Expression {
 					expr = CallExpr{Function: expr, Args: []Expression{}, Line: line, Column: column}
 					continue
 				}
-				expr = CallExpr{Function: expr, Args: parser.parseExpressionArray(), Line: line, Column: column}
+				if isCName(expr) {
+					expr = CallExpr{Function: expr, Args: parser.parseCArguments(), Line: line, Column: column}
+				} else {
+					expr = CallExpr{Function: expr, Args: parser.parseExpressionArray(), Line: line, Column: column}
+				}
 
 				parser.expect(RightParen, SecondaryNullType)
 				parser.eatLastToken()
@@ -1243,8 +1367,16 @@ func (parser *Parser) parseExpr(state int) Expression {
 		case Identifier:
 			parser.eatLastToken()
 			return IdentExpr{Value: token, Line: line, Column: column}
+		case PromiseKeyword:
+			// the keyword is the base of promise.all, promise.race and promise.any
+			parser.eatLastToken()
+			parser.expect(PrimaryNullType, Dot)
+			return IdentExpr{Value: token, Line: line, Column: column}
 		case StringLiteral:
 			parser.eatLastToken()
+			if token.SecondaryType == Interpolated {
+				return parser.parseInterpolatedString(token)
+			}
 			return BasicLit{Value: token, Line: line, Column: column}
 		case CharLiteral:
 			parser.eatLastToken()
@@ -1261,12 +1393,77 @@ func (parser *Parser) parseExpr(state int) Expression {
 			return expr
 		}
 
-		return nil
+		parser.error("expected an expression, got '"+token.Serialize()+"'.", token.Line, token.Column)
 	}
 
 	return nil
 }
 
+// parseInterpolatedString splits a string literal at its braces, the text between them is lexed again as string literals
+// and the expressions in them are parsed with their own parser, at their position in the file
+func (parser *Parser) parseInterpolatedString(token Token) InterpolatedString {
+	str := InterpolatedString{Line: token.Line, Column: token.Column}
+	buff := token.Buff[1 : len(token.Buff)-1]
+	start := 0
+
+	for x := 0; x < len(buff); x++ {
+		if buff[x] == '\\' {
+			x++
+			continue
+		} else if buff[x] != '{' || x+1 == len(buff) || !StartsInterpolation(buff[x+1]) {
+			continue
+		}
+
+		str.Texts = append(str.Texts, parser.interpolationText(buff[start:x], token.Line, token.Column+1+start))
+		end := interpolationEnd(buff, x)
+
+		sub := Parser{Lexer: &Lexer{Buffer: buff[x+1 : end], Line: token.Line, Column: token.Column + 2 + x, Path: parser.Lexer.Path}, Forks: map[byte]int{}}
+		str.Exprs = append(str.Exprs, sub.parseExpression())
+
+		if next := sub.ReadToken(); next.PrimaryType != EOF {
+			sub.error("expected '}', got '"+next.Serialize()+"'.", next.Line, next.Column)
+		}
+		x, start = end, end+1
+	}
+
+	str.Texts = append(str.Texts, parser.interpolationText(buff[start:], token.Line, token.Column+1+start))
+	return str
+}
+
+// interpolationText returns the text between the braces of an interpolated string as a string literal
+func (parser *Parser) interpolationText(text []byte, line, column int) BasicLit {
+	lexer := &Lexer{Buffer: append(append([]byte{'"'}, text...), '"'), Line: line, Column: column - 1, Path: parser.Lexer.Path}
+	token := lexer.NextToken()
+	return BasicLit{Value: token, Line: line, Column: column - 1}
+}
+
+// interpolationEnd returns the position of the brace matching the one at start, the lexer already checked that there is one
+func interpolationEnd(buff []byte, start int) int {
+	depth := 0
+	var quote byte
+
+	for x := start; x < len(buff); x++ {
+		switch c := buff[x]; {
+		case quote != 0 && c == '\\':
+			x++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return x
+			}
+		}
+	}
+	return len(buff)
+}
+
 func (parser *Parser) parseFunctionExpr() FuncExpr {
 	line, column := parser.pos()
 	function := FuncExpr{Line: line, Column: column, Type: FuncType{Line: line, Column: column}}
@@ -1432,6 +1629,10 @@ func (parser *Parser) parseTypeAHH(state int) Type {
 func (parser *Parser) parseExprOrType() Expression {
 	switch parser.ReadToken().PrimaryType {
 	case PromiseKeyword:
+		// promise.all and the other combinators are expressions
+		if parser.peekToken().SecondaryType == Dot {
+			return parser.parseExpr(0)
+		}
 	case ConstKeyword:
 	case VecKeyword:
 	case LeftBrace:
@@ -69,6 +69,7 @@ const (
 	StaticKeyword   PrimaryTokenType = 129
 	CaptureKeyword  PrimaryTokenType = 130
 	PromiseKeyword  PrimaryTokenType = 131
+	AssertKeyword   PrimaryTokenType = 132
 
 	// the parser stops parsing when it receives either of these types and shows the correct error message
 	EOF        PrimaryTokenType = 254
@@ -135,6 +136,10 @@ const (
 	Byte3Char SecondaryTokenType = 73
 	Byte4Char SecondaryTokenType = 74
 
+	// Kinds of string literals
+	RawString    SecondaryTokenType = 81
+	Interpolated SecondaryTokenType = 82
+
 	// For use with ErrorToken
 	UnexpectedEOF SecondaryTokenType = 101
 	NotFound      SecondaryTokenType = 102
@@ -166,13 +171,14 @@ var Keywords = map[string]PrimaryTokenType{
 	"delete":   DeleteKeyword,
 	"typedef":  TypedefKeyword,
 	"cast":     CastKeyword,
-	// "len":      LenKeyword,
+	"len":      LenKeyword,
 	"sizeof":  SizeKeyword,
 	"export":  ExportKeyword,
 	"union":   UnionKeyword,
 	"static":  StaticKeyword,
 	"capture": CaptureKeyword,
 	"promise": PromiseKeyword,
+	"assert":  AssertKeyword,
 	// more stuff
 }
 
@@ -184,6 +190,13 @@ type Token struct {
 	Line          int
 	Column        int
 	Flags         int
+
+	// the source text of a char literal with its quotes, the printer writes it back as it was written
+	Raw []byte
+
+	// trivia before the token, only recorded when the lexer's KeepComments is set
+	Comments   []Comment
+	BlankLines int
 }
 
 var PrimaryTypes map[PrimaryTokenType]string = map[PrimaryTokenType]string{
@@ -236,12 +249,14 @@ var PrimaryTypes map[PrimaryTokenType]string = map[PrimaryTokenType]string{
 	DeleteKeyword:   "delete",
 	TypedefKeyword:  "typedef",
 	CastKeyword:     "cast",
+	LenKeyword:      "len",
 	SizeKeyword:     "sizeof",
 	ExportKeyword:   "export",
 	UnionKeyword:    "union",
 	StaticKeyword:   "static",
 	CaptureKeyword:  "capture",
 	PromiseKeyword:  "promise",
+	AssertKeyword:   "assert",
 
 	EOF:        "EOF",
 	ErrorToken: "ErrorToken",
@@ -298,6 +313,9 @@ var SecondaryTypes map[SecondaryTokenType]string = map[SecondaryTokenType]string
 	Byte3Char: "Byte3Char",
 	Byte4Char: "Byte4Char",
 
+	RawString:    "RawString",
+	Interpolated: "Interpolated",
+
 	UnexpectedEOF: "UnexpectedEOF",
 	NotFound:      "NotFound",
 	UnknownChar:   "UnknownChar",
@@ -1,5 +1,11 @@
 package parser
 
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
 //IsChar checks if `b` is an english alphabet `(a-z|A-Z)`
 func IsChar(b byte) bool {
 	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
@@ -25,6 +31,16 @@ func IsNumHex(b byte) bool {
 	return (b >= '0' && b <= '9') || (b >= 'A' && b <= 'F') || (b >= 'a' && b <= 'f')
 }
 
+// StartsInterpolation reports whether a { followed by b starts an expression in a string literal,
+// a { followed by }, a space or any other byte that can not start an expression is kept as it is
+func StartsInterpolation(b byte) bool {
+	switch b {
+	case '_', '(', '{', '"', '\'', '-', '+', '!', '~', '*', '&', '$':
+		return true
+	}
+	return IsChar(b) || IsNumDec(b) || b >= utf8.RuneSelf
+}
+
 //IsIdentifierPart checks if `b` is a valid character for being a part of identifier `(a-z|A-Z|0-9|_)`
 func IsIdentifierPart(b byte) bool {
 	return IsChar(b) || IsNumDec(b) || b == '_' || b == '$'
@@ -35,11 +51,32 @@ func IsIdentifierBegining(b byte) bool {
 	return IsChar(b) || b == '_' || b == '$'
 }
 
+//IsIdentifierStart checks if `r` is valid for being the first character of an identifier, unicode letters are allowed
+func IsIdentifierStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentifierBegining(byte(r))
+	}
+	return unicode.IsLetter(r)
+}
+
+//IsIdentifierRune checks if `r` is a valid character for being a part of identifier, unicode letters, digits and combining marks are allowed
+func IsIdentifierRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentifierPart(byte(r))
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
+
 //IsStringDelimiter checks if `b` is a string delimiter `"`
 func IsStringDelimiter(b byte) bool {
 	return b == '"'
 }
 
+//IsRawStringDelimiter checks if `b` is a raw string delimiter "`"
+func IsRawStringDelimiter(b byte) bool {
+	return b == '`'
+}
+
 //IsCharDelimiter checks if `b` is a char delimiter `'`
 func IsCharDelimiter(b byte) bool {
 	return b == '\''
@@ -68,8 +105,68 @@ func Pow(a, b int) int {
 
 func HexToInt(b byte) int {
 	if IsNumDec(b) {
-		return int(b - 48)
+		return int(b - '0')
+	} else if b >= 'a' {
+		return int(b-'a') + 10
 	} else {
-		return int(b - 65)
+		return int(b-'A') + 10
+	}
+}
+
+// Unescape replaces the escape sequences of a string literal with the bytes they stand for
+func Unescape(str []byte) []byte {
+	buf := []byte{}
+
+	for x := 0; x < len(str); x++ {
+		if str[x] != '\\' || x+1 == len(str) {
+			buf = append(buf, str[x])
+			continue
+		}
+		x++
+
+		switch c := str[x]; c {
+		case 'n':
+			buf = append(buf, '\n')
+		case 't':
+			buf = append(buf, '\t')
+		case 'r':
+			buf = append(buf, '\r')
+		case 'a':
+			buf = append(buf, '\a')
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'v':
+			buf = append(buf, '\v')
+		case 'e':
+			buf = append(buf, 27)
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			end := x + 1
+			for end < len(str) && end-x <= digits && IsNumHex(str[end]) {
+				end++
+			}
+			n, _ := strconv.ParseUint(string(str[x+1:end]), 16, 32)
+			if c == 'x' {
+				buf = append(buf, byte(n))
+			} else {
+				buf = append(buf, string(rune(n))...)
+			}
+			x = end - 1
+		default:
+			if c < '0' || c > '7' {
+				buf = append(buf, c)
+				break
+			}
+			end := x
+			for end < len(str) && end-x < 3 && str[end] >= '0' && str[end] <= '7' {
+				end++
+			}
+			n, _ := strconv.ParseUint(string(str[x:end]), 8, 32)
+			buf = append(buf, byte(n))
+			x = end - 1
+		}
 	}
+	return buf
 }
@@ -0,0 +1,935 @@
+package printer
+
+import (
+	"bytes"
+	. "parser"
+	"strconv"
+)
+
+// Indent is the string used for one level of indentation
+var Indent = []byte("    ")
+
+type Printer struct {
+	Buff       []byte
+	Comments   []Comment
+	BlankLines []int
+	Depth      int
+}
+
+// PrintFile returns the canonical source of ast, comments and blank lines recorded by the lexer are kept
+func PrintFile(ast File) []byte {
+	p := Printer{Comments: ast.Comments, BlankLines: ast.BlankLines}
+
+	var prev Statement
+	for _, stmt := range ast.Statements {
+		switch stmt.(type) {
+		case NullStatement:
+			continue
+		}
+
+		if prev != nil && (isMultiline(prev) || isMultiline(stmt)) {
+			p.flushTrailing(stmt.LineM())
+			p.newline()
+		}
+		p.statement(stmt)
+		prev = stmt
+	}
+
+	p.flushComments(-1)
+	return p.Buff
+}
+
+// PrintType returns the source of typ
+func PrintType(typ Type) []byte {
+	p := Printer{}
+	p.typ(typ)
+	return p.Buff
+}
+
+// PrintExpression returns the source of expr
+func PrintExpression(expr Expression) []byte {
+	p := Printer{}
+	p.expression(expr)
+	return p.Buff
+}
+
+// PrintTypedef returns the source of typedef
+func PrintTypedef(typedef Typedef) []byte {
+	p := Printer{}
+	p.typedef(typedef)
+	return p.Buff
+}
+
+// PrintFuncHead returns the source of a function declaration without its body
+func PrintFuncHead(name Token, typ FuncType) []byte {
+	p := Printer{}
+	p.funcHead(name, typ)
+	return p.Buff
+}
+
+// isMultiline checks if a global statement spans multiple lines, these are always separated by a blank line
+func isMultiline(stmt Statement) bool {
+	switch stmt.(type) {
+	case ExportStatement:
+		return isMultiline(stmt.(ExportStatement).Stmt)
+	case Typedef:
+		switch stmt.(Typedef).Type.(type) {
+		case StructType, EnumType, UnionType:
+			return true
+		}
+	case Declaration:
+		for _, value := range stmt.(Declaration).Values {
+			if _, ok := value.(FuncExpr); ok {
+				return true
+			}
+		}
+	case Test:
+		return true
+	}
+	return false
+}
+
+func (p *Printer) append(buff []byte) {
+	p.Buff = append(p.Buff, buff...)
+}
+
+func (p *Printer) str(s string) {
+	p.Buff = append(p.Buff, s...)
+}
+
+func (p *Printer) space() {
+	p.str(" ")
+}
+
+func (p *Printer) semicolon() {
+	p.str(";")
+}
+
+func (p *Printer) newline() {
+	p.str("\n")
+}
+
+func (p *Printer) indent() {
+	for i := 0; i < p.Depth; i++ {
+		p.append(Indent)
+	}
+}
+
+func (p *Printer) pushScope() {
+	p.Depth++
+}
+
+func (p *Printer) popScope() {
+	p.Depth--
+}
+
+// skipBlankLines drops the blank lines before line, returns true if there were any
+func (p *Printer) skipBlankLines(line int) bool {
+	skipped := false
+	for len(p.BlankLines) > 0 && (line == -1 || p.BlankLines[0] < line) {
+		p.BlankLines = p.BlankLines[1:]
+		skipped = true
+	}
+	return skipped
+}
+
+// blankLine prints a single blank line if there were any in the source before line
+func (p *Printer) blankLine(line int) {
+	if !p.skipBlankLines(line) || len(p.Buff) == 0 {
+		return
+	}
+	for _, suffix := range []string{"\n\n", "{\n", ":\n"} {
+		if bytes.HasSuffix(p.Buff, []byte(suffix)) {
+			return
+		}
+	}
+	p.newline()
+}
+
+// flushComments prints all the comments which start before line, all of them if line is -1
+func (p *Printer) flushComments(line int) {
+	for len(p.Comments) > 0 && (line == -1 || p.Comments[0].Line < line) {
+		comment := p.Comments[0]
+		p.Comments = p.Comments[1:]
+
+		if comment.Trailing && bytes.HasSuffix(p.Buff, []byte("\n")) {
+			p.Buff = p.Buff[:len(p.Buff)-1]
+			p.space()
+			p.append(comment.Text)
+			p.newline()
+			continue
+		}
+
+		p.blankLine(comment.Line)
+		p.indent()
+		p.append(comment.Text)
+		p.newline()
+	}
+}
+
+// flushTrailing prints the trailing comments which start before line
+func (p *Printer) flushTrailing(line int) {
+	for len(p.Comments) > 0 && p.Comments[0].Trailing && p.Comments[0].Line < line {
+		p.flushComments(p.Comments[0].Line + 1)
+	}
+}
+
+// line starts a new line for a node at srcLine, printing the comments before it
+func (p *Printer) line(srcLine int) {
+	if srcLine > 0 {
+		p.flushComments(srcLine)
+		p.blankLine(srcLine)
+	}
+	p.indent()
+}
+
+func (p *Printer) imprt(imprt Import) {
+	p.str("import ")
+
+	if len(imprt.Paths) == 1 {
+		p.append(imprt.Paths[0].Buff)
+		p.semicolon()
+		return
+	}
+
+	p.str("(")
+	for i, path := range imprt.Paths {
+		if i > 0 {
+			p.str(", ")
+		}
+		p.append(path.Buff)
+	}
+	p.str(");")
+}
+
+func (p *Printer) statement(stmt Statement) {
+	switch stmt.(type) {
+	case NullStatement:
+		return
+	}
+	p.line(stmt.LineM())
+	p.statementNoIndent(stmt)
+}
+
+// statementNoIndent prints stmt followed by a newline, the indentation must already be printed
+func (p *Printer) statementNoIndent(stmt Statement) {
+	switch stmt.(type) {
+	case Declaration:
+		dec := stmt.(Declaration)
+		p.declaration(dec)
+		if !isFuncDec(dec) {
+			p.semicolon()
+		}
+	case Typedef:
+		p.typedef(stmt.(Typedef))
+	case ExportStatement:
+		p.str("export ")
+		p.statementNoIndent(stmt.(ExportStatement).Stmt)
+		return
+	case Return:
+		p.rturn(stmt.(Return))
+	case IfElseBlock:
+		p.ifElse(stmt.(IfElseBlock))
+	case Loop:
+		p.loop(stmt.(Loop))
+	case Switch:
+		p.swtch(stmt.(Switch))
+	case Assignment:
+		p.assignment(stmt.(Assignment))
+		p.semicolon()
+	case Break:
+		p.str("break;")
+	case Continue:
+		p.str("continue;")
+	case Block:
+		p.block(stmt.(Block))
+	case Defer:
+		p.str("defer ")
+		p.simpleStatement(stmt.(Defer).Stmt)
+		p.semicolon()
+	case Delete:
+		p.str("delete ")
+		p.expressionList(stmt.(Delete).Exprs)
+		p.semicolon()
+	case Import:
+		p.imprt(stmt.(Import))
+	case Test:
+		p.str("test ")
+		p.append(stmt.(Test).Name.Buff)
+		p.str(" ")
+		p.block(stmt.(Test).Block)
+	case Assert:
+		p.str("assert ")
+		p.expression(stmt.(Assert).Cond)
+		p.semicolon()
+	case NullStatement:
+		p.semicolon()
+	case Expression:
+		p.expression(stmt.(Expression))
+		p.semicolon()
+	}
+	p.newline()
+}
+
+// simpleStatement prints statements used as loop, if and switch headers
+func (p *Printer) simpleStatement(stmt Statement) {
+	switch stmt.(type) {
+	case Declaration:
+		p.declaration(stmt.(Declaration))
+	case Assignment:
+		p.assignment(stmt.(Assignment))
+	case Expression:
+		p.expression(stmt.(Expression))
+	}
+}
+
+func isFuncDec(dec Declaration) bool {
+	if len(dec.Types) != 1 || len(dec.Values) != 1 {
+		return false
+	}
+	typ, ok := dec.Types[0].(FuncType)
+	if !ok || typ.Mut {
+		return false
+	}
+	_, ok = dec.Values[0].(FuncExpr)
+	return ok
+}
+
+func (p *Printer) declaration(dec Declaration) {
+	if isFuncDec(dec) {
+		p.funcDec(dec.Identifiers[0], dec.Values[0].(FuncExpr))
+		return
+	}
+
+	for i, ident := range dec.Identifiers {
+		if i > 0 {
+			p.str(", ")
+		}
+		p.append(ident.Buff)
+	}
+
+	if len(dec.Types) > 0 {
+		p.str(": ")
+		for i, typ := range dec.Types {
+			if i > 0 {
+				p.str(", ")
+			}
+			p.typ(typ)
+		}
+		if len(dec.Values) > 0 {
+			p.str(" = ")
+		}
+	} else {
+		p.str(" := ")
+	}
+
+	p.expressionList(dec.Values)
+}
+
+func funcKind(typ FunctionType) string {
+	switch typ {
+	case AsyncFunction:
+		return "async"
+	case WorkFunction:
+		return "work"
+	}
+	return ""
+}
+
+func isVoid(typ Type) bool {
+	if basic, ok := typ.(BasicType); ok {
+		if ident, ok := basic.Expr.(IdentExpr); ok {
+			return string(ident.Value.Buff) == "$void"
+		}
+	}
+	return false
+}
+
+func (p *Printer) funcDec(name Token, fnc FuncExpr) {
+	p.funcHead(name, fnc.Type)
+	p.space()
+	p.block(fnc.Block)
+}
+
+func (p *Printer) funcHead(name Token, typ FuncType) {
+	p.str("func ")
+	if kind := funcKind(typ.Type); kind != "" {
+		p.str(kind + " ")
+	}
+	p.append(name.Buff)
+	p.funcSignature(typ, true)
+}
+
+// funcSignature prints the arguments and return type of a function, argument names are printed only when withNames is set
+func (p *Printer) funcSignature(typ FuncType, withNames bool) {
+	p.str("(")
+	for i, arg := range typ.ArgTypes {
+		if isVoid(arg) && len(typ.ArgTypes) == 1 && len(typ.ArgNames) == 0 {
+			break
+		}
+		if i > 0 {
+			p.str(", ")
+		}
+		if withNames && i < len(typ.ArgNames) {
+			p.append(typ.ArgNames[i].Buff)
+			p.str(": ")
+		}
+		p.typ(arg)
+	}
+	p.str(")")
+
+	if len(typ.ReturnTypes) > 0 && !isVoid(typ.ReturnTypes[0]) {
+		p.space()
+		p.typ(typ.ReturnTypes[0])
+	}
+}
+
+func (p *Printer) typedef(typedef Typedef) {
+	switch typedef.Type.(type) {
+	case StructType:
+		p.str("struct ")
+		p.append(typedef.Name.Buff)
+		p.space()
+		p.strct(typedef.Type.(StructType))
+	case EnumType:
+		p.str("enum ")
+		p.append(typedef.Name.Buff)
+		p.space()
+		p.enum(typedef.Type.(EnumType))
+	case TupleType:
+		p.str("tuple ")
+		p.append(typedef.Name.Buff)
+		p.space()
+		p.tupl(typedef.Type.(TupleType))
+	case UnionType:
+		p.str("union ")
+		p.append(typedef.Name.Buff)
+		p.space()
+		p.union(typedef.Type.(UnionType))
+	default:
+		p.str("typedef ")
+		p.append(typedef.Name.Buff)
+		p.space()
+		p.typ(typedef.Type)
+	}
+	p.semicolon()
+}
+
+func (p *Printer) strct(strct StructType) {
+	p.str("{\n")
+	p.pushScope()
+
+	for _, super := range strct.SuperStructs {
+		p.line(super.LineM())
+		p.str("..")
+		p.expression(super)
+		p.str(";\n")
+	}
+	for _, prop := range strct.Props {
+		p.statement(prop)
+	}
+
+	p.flushComments(strct.EndLine)
+	p.skipBlankLines(strct.EndLine)
+	p.popScope()
+	p.indent()
+	p.str("}")
+}
+
+func (p *Printer) enum(enum EnumType) {
+	p.str("{\n")
+	p.pushScope()
+
+	for i, ident := range enum.Identifiers {
+		p.line(ident.Line)
+		p.append(ident.Buff)
+		if i < len(enum.Values) && enum.Values[i] != nil {
+			p.str(" = ")
+			p.expression(enum.Values[i])
+		}
+		p.str(",\n")
+	}
+
+	p.popScope()
+	p.indent()
+	p.str("}")
+}
+
+func (p *Printer) tupl(tupl TupleType) {
+	p.str("{")
+	for i, typ := range tupl.Types {
+		if i > 0 {
+			p.str(", ")
+		}
+		p.typ(typ)
+	}
+	p.str("}")
+}
+
+func (p *Printer) union(union UnionType) {
+	p.str("{\n")
+	p.pushScope()
+
+	for i, ident := range union.Identifiers {
+		p.line(ident.Line)
+		p.append(ident.Buff)
+		p.str(": ")
+		p.typ(union.Types[i])
+		p.str(";\n")
+	}
+
+	p.popScope()
+	p.indent()
+	p.str("}")
+}
+
+func (p *Printer) rturn(rtrn Return) {
+	p.str("return")
+
+	values := []Expression{}
+	for _, value := range rtrn.Values {
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+
+	if len(values) > 0 {
+		p.space()
+		p.expressionList(values)
+	}
+	p.semicolon()
+}
+
+func (p *Printer) ifElse(ifElse IfElseBlock) {
+	for i, cond := range ifElse.Conditions {
+		if i == 0 {
+			p.str("if ")
+			if ifElse.HasInitStmt {
+				p.simpleStatement(ifElse.InitStatement)
+				p.str("; ")
+			}
+		} else {
+			p.str(" else if ")
+		}
+		p.expression(cond)
+		p.space()
+		p.block(ifElse.Blocks[i])
+	}
+
+	if ifElse.ElseBlock.Line > 0 {
+		p.str(" else ")
+		p.block(ifElse.ElseBlock)
+	}
+}
+
+func (p *Printer) loop(loop Loop) {
+	p.str("for ")
+
+	if loop.Type&InitLoop == InitLoop {
+		p.simpleStatement(loop.InitStatement)
+		p.str("; ")
+
+		if loop.Type&CondLoop == CondLoop {
+			p.expression(loop.Condition)
+		}
+		if loop.Type&LoopLoop == LoopLoop {
+			p.str("; ")
+			p.simpleStatement(loop.LoopStatement)
+		}
+		p.space()
+	} else if loop.Type&CondLoop == CondLoop {
+		p.expression(loop.Condition)
+		p.space()
+	}
+
+	p.block(loop.Block)
+}
+
+func (p *Printer) swtch(swtch Switch) {
+	p.str("switch ")
+
+	if swtch.InitStatement != nil {
+		p.simpleStatement(swtch.InitStatement)
+		p.str("; ")
+	}
+	if swtch.Expr != nil {
+		p.expression(swtch.Expr)
+		p.space()
+	}
+	p.str("{\n")
+
+	for _, Case := range swtch.Cases {
+		p.line(Case.Line)
+		p.str("case ")
+		p.expression(Case.Condition)
+		p.str(":\n")
+
+		p.pushScope()
+		for _, stmt := range Case.Block.Statements {
+			p.statement(stmt)
+		}
+		p.popScope()
+	}
+
+	if swtch.HasDefaultCase {
+		p.line(swtch.DefaultCase.Line)
+		p.str("default:\n")
+
+		p.pushScope()
+		for _, stmt := range swtch.DefaultCase.Statements {
+			p.statement(stmt)
+		}
+		p.popScope()
+	}
+
+	p.indent()
+	p.str("}")
+}
+
+func (p *Printer) assignment(as Assignment) {
+	p.expressionList(as.Variables)
+
+	if as.Op.SecondaryType == AddAdd || as.Op.SecondaryType == SubSub {
+		p.append(as.Op.Buff)
+		return
+	}
+
+	p.space()
+	p.append(as.Op.Buff)
+	p.space()
+	p.expressionList(as.Values)
+}
+
+func (p *Printer) block(block Block) {
+	p.str("{\n")
+	p.pushScope()
+
+	for _, stmt := range block.Statements {
+		p.statement(stmt)
+	}
+
+	if block.EndLine > 0 {
+		p.flushComments(block.EndLine)
+		p.skipBlankLines(block.EndLine)
+	}
+	p.popScope()
+	p.indent()
+	p.str("}")
+}
+
+func (p *Printer) expressionList(exprs []Expression) {
+	for i, expr := range exprs {
+		if i > 0 {
+			p.str(", ")
+		}
+		p.expression(expr)
+	}
+}
+
+// Precedence levels, same as the states of parser.parseExpr
+const (
+	ternaryPrec = iota
+	logicalPrec
+	bitwisePrec
+	equalityPrec
+	relationalPrec
+	shiftPrec
+	additivePrec
+	multiplicativePrec
+	unaryPrec
+	postfixPrec
+	primaryPrec
+)
+
+func binaryPrec(op Token) int {
+	switch op.SecondaryType {
+	case AndAnd, OrOr:
+		return logicalPrec
+	case Or, And, ExclusiveOr:
+		return bitwisePrec
+	case EqualEqual, NotEqual:
+		return equalityPrec
+	case Greater, Less, GreaterEqual, LessEqual:
+		return relationalPrec
+	case LeftShift, RightShift:
+		return shiftPrec
+	case Add, Sub:
+		return additivePrec
+	}
+	return multiplicativePrec
+}
+
+func precedence(expr Expression) int {
+	switch expr.(type) {
+	case TernaryExpr:
+		return ternaryPrec
+	case BinaryExpr:
+		return binaryPrec(expr.(BinaryExpr).Op)
+	case UnaryExpr, TypeCast, HeapAlloc, LenExpr, SizeExpr:
+		return unaryPrec
+	case CallExpr, PostfixUnaryExpr, ArrayMemberExpr, MemberExpr, PointerMemberExpr:
+		return postfixPrec
+	}
+	return primaryPrec
+}
+
+// operand prints expr, wrapped in parentheses if it binds looser than prec
+func (p *Printer) operand(expr Expression, prec int) {
+	if precedence(expr) < prec {
+		p.str("(")
+		p.expression(expr)
+		p.str(")")
+		return
+	}
+	p.expression(expr)
+}
+
+func (p *Printer) expression(expr Expression) {
+	switch expr.(type) {
+	case BasicLit:
+		p.basicLit(expr.(BasicLit).Value)
+	case IdentExpr:
+		p.append(expr.(IdentExpr).Value.Buff)
+	case BinaryExpr:
+		bin := expr.(BinaryExpr)
+		prec := binaryPrec(bin.Op)
+
+		p.operand(bin.Left, prec)
+		p.space()
+		p.append(bin.Op.Buff)
+		p.space()
+		p.operand(bin.Right, prec+1)
+	case UnaryExpr:
+		unary := expr.(UnaryExpr)
+		p.append(unary.Op.Buff)
+
+		// avoid printing tokens which lex differently when joined, like `- -a` or `& &a`
+		if inner, ok := unary.Expr.(UnaryExpr); ok && inner.Op.Buff[0] == unary.Op.Buff[len(unary.Op.Buff)-1] {
+			p.str("(")
+			p.expression(inner)
+			p.str(")")
+			return
+		}
+		p.operand(unary.Expr, unaryPrec)
+	case PostfixUnaryExpr:
+		postfix := expr.(PostfixUnaryExpr)
+		p.operand(postfix.Expr, postfixPrec)
+		p.append(postfix.Op.Buff)
+	case TernaryExpr:
+		ternary := expr.(TernaryExpr)
+		p.operand(ternary.Cond, logicalPrec)
+		p.str(" ? ")
+		p.expression(ternary.Left)
+		p.str(" : ")
+		p.operand(ternary.Right, logicalPrec)
+	case FuncExpr:
+		fnc := expr.(FuncExpr)
+		p.str("func")
+		if kind := funcKind(fnc.Type.Type); kind != "" {
+			p.str(" " + kind)
+		}
+		p.funcSignature(fnc.Type, true)
+		p.space()
+		p.block(fnc.Block)
+	case CallExpr:
+		call := expr.(CallExpr)
+		p.operand(call.Function, postfixPrec)
+		p.str("(")
+		p.expressionList(call.Args)
+		p.str(")")
+	case TypeCast:
+		cast := expr.(TypeCast)
+		p.str("cast(")
+		p.typ(cast.Type)
+		p.str(")")
+		p.operand(cast.Expr, unaryPrec)
+	case MemberExpr:
+		member := expr.(MemberExpr)
+		p.operand(member.Base, postfixPrec)
+		p.str(".")
+		p.append(member.Prop.Buff)
+	case PointerMemberExpr:
+		member := expr.(PointerMemberExpr)
+		p.operand(member.Base, postfixPrec)
+		p.str(".")
+		p.append(member.Prop.Buff)
+	case ArrayMemberExpr:
+		member := expr.(ArrayMemberExpr)
+		p.operand(member.Parent, postfixPrec)
+		p.str("[")
+		p.expression(member.Index)
+		p.str("]")
+	case CompoundLiteral:
+		literal := expr.(CompoundLiteral)
+		p.str("(")
+		p.typ(literal.Name)
+		p.str(")")
+		p.compoundLiteralData(literal.Data)
+	case ArrayLiteral:
+		p.str("{")
+		p.expressionList(expr.(ArrayLiteral).Exprs)
+		p.str("}")
+	case HeapAlloc:
+		alloc := expr.(HeapAlloc)
+		p.str("new ")
+		p.typ(alloc.Type)
+
+		switch alloc.Val.(type) {
+		case nil:
+		case CompoundLiteral:
+			p.compoundLiteralData(alloc.Val.(CompoundLiteral).Data)
+		default:
+			p.str("(")
+			p.expression(alloc.Val)
+			p.str(")")
+		}
+	case InterpolatedString:
+		str := expr.(InterpolatedString)
+		p.str("\"")
+		for x, text := range str.Texts {
+			p.append(text.Value.Buff[1 : len(text.Value.Buff)-1])
+			if x < len(str.Exprs) {
+				p.str("{")
+				p.expression(str.Exprs[x])
+				p.str("}")
+			}
+		}
+		p.str("\"")
+	case LenExpr:
+		p.str("len(")
+		p.typ(expr.(LenExpr).Type)
+		p.str(")")
+	case SizeExpr:
+		p.str("sizeof(")
+		p.expression(expr.(SizeExpr).Expr)
+		p.str(")")
+	case Type:
+		p.typ(expr.(Type))
+	}
+}
+
+func (p *Printer) compoundLiteralData(data CompoundLiteralData) {
+	p.str("{")
+	for i, value := range data.Values {
+		if i > 0 {
+			p.str(", ")
+		}
+		if i < len(data.Fields) {
+			p.append(data.Fields[i].Buff)
+			p.str(": ")
+		}
+		p.expression(value)
+	}
+	p.str("}")
+}
+
+func (p *Printer) basicLit(token Token) {
+	switch token.PrimaryType {
+	case CharLiteral:
+		p.charLit(token)
+	case NumberLiteral:
+		switch token.SecondaryType {
+		case OctalRadix:
+			p.str("0o")
+			p.append(token.Buff[1:])
+		default:
+			p.append(token.Buff)
+		}
+	default:
+		p.append(token.Buff)
+	}
+}
+
+// charLit prints a char literal as it was written, or rebuilds it from the code point the lexer stores in its Buff
+func (p *Printer) charLit(token Token) {
+	if token.Raw != nil {
+		p.append(token.Raw)
+		return
+	}
+
+	num, _ := strconv.Atoi(string(token.Buff))
+	p.str("'")
+
+	switch {
+	case num == '\t':
+		p.str("\\t")
+	case num == '\n':
+		p.str("\\n")
+	case num == '\r':
+		p.str("\\r")
+	case num == '\'':
+		p.str("\\'")
+	case num == '\\':
+		p.str("\\\\")
+	case num >= 32 && num < 127:
+		p.Buff = append(p.Buff, byte(num))
+	case num <= 0xFFFF:
+		p.str("\\u" + hex(num, 4))
+	default:
+		p.str("\\U" + hex(num, 8))
+	}
+
+	p.str("'")
+}
+
+func hex(num int, digits int) string {
+	s := strconv.FormatInt(int64(num), 16)
+	for len(s) < digits {
+		s = "0" + s
+	}
+	return s
+}
+
+func (p *Printer) typ(typ Type) {
+	switch typ.(type) {
+	case BasicType:
+		p.expression(typ.(BasicType).Expr)
+	case PointerType:
+		p.str("*")
+		p.typ(typ.(PointerType).BaseType)
+	case VecType:
+		p.str("vec ")
+		p.typ(typ.(VecType).BaseType)
+	case ConstType:
+		p.str("const ")
+		p.typ(typ.(ConstType).BaseType)
+	case CaptureType:
+		p.str("capture ")
+		p.typ(typ.(CaptureType).BaseType)
+	case StaticType:
+		p.str("static ")
+		p.typ(typ.(StaticType).BaseType)
+	case PromiseType:
+		p.str("promise ")
+		p.typ(typ.(PromiseType).BaseType)
+	case ImplictArrayType:
+		p.str("[]")
+		p.typ(typ.(ImplictArrayType).BaseType)
+	case ArrayType:
+		p.str("[")
+		p.basicLit(typ.(ArrayType).Size)
+		p.str("]")
+		p.typ(typ.(ArrayType).BaseType)
+	case FuncType:
+		fnc := typ.(FuncType)
+		p.str("func")
+		if kind := funcKind(fnc.Type); kind != "" {
+			p.str(" " + kind)
+		}
+		p.funcSignature(fnc, false)
+	case StructType:
+		p.str("struct ")
+		p.strct(typ.(StructType))
+	case TupleType:
+		p.str("tuple ")
+		p.tupl(typ.(TupleType))
+	case EnumType:
+		p.str("enum ")
+		p.enum(typ.(EnumType))
+	case UnionType:
+		p.str("union ")
+		p.union(typ.(UnionType))
+	case Typedef:
+		p.append(typ.(Typedef).Name.Buff)
+	}
+}
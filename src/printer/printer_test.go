@@ -0,0 +1,149 @@
+package printer_test
+
+import (
+	"bytes"
+	"dump"
+	"error"
+	"io/ioutil"
+	"parser"
+	"path/filepath"
+	"printer"
+	"regexp"
+	"testing"
+)
+
+// sources are the examples, the compiler fixtures and the standard library, the files that do not parse are skipped
+func sources(t *testing.T) []string {
+	examples, _ := filepath.Glob("../../examples/*.vo")
+	fixtures, _ := filepath.Glob("../compiler/testdata/fixtures/*.vo")
+	lib, _ := filepath.Glob("../../lib/*.vo")
+
+	if len(examples) == 0 || len(fixtures) == 0 || len(lib) == 0 {
+		t.Fatal("no sources found")
+	}
+	return append(append(examples, fixtures...), lib...)
+}
+
+func parse(code []byte, file string) (ast parser.File, err *error.Error) {
+	err = error.Catch(func() {
+		ast = parser.ParseFile(&parser.Lexer{Buffer: code, Line: 1, Column: 1, Path: file, KeepComments: true})
+	})
+	return
+}
+
+var positions = regexp.MustCompile(`(?m) @\d+:\d+| EndLine=\d+|^ *- NullStatement\n`)
+
+// tree dumps ast without positions, the printer drops parentheses and empty statements the parser does not keep
+func tree(ast parser.File) string {
+	ast.Comments, ast.BlankLines = nil, nil
+	return positions.ReplaceAllString(string(dump.Text(dump.Value(ast))), "")
+}
+
+// comments lists the text of the comments in code
+func comments(code []byte) []string {
+	lexer := &parser.Lexer{Buffer: code, Line: 1, Column: 1, KeepComments: true}
+	for token := lexer.NextToken(); token.PrimaryType != parser.EOF; token = lexer.NextToken() {
+	}
+
+	list := []string{}
+	for _, comment := range lexer.Comments {
+		list = append(list, string(bytes.TrimSpace(comment.Text)))
+	}
+	return list
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, file := range sources(t) {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			code, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast, perr := parse(code, file)
+			if perr != nil {
+				t.Skip(perr.Error())
+			}
+
+			once := printer.PrintFile(ast)
+			again, perr := parse(once, file)
+			if perr != nil {
+				t.Fatalf("formatted source does not parse: %s\n%s", perr.Error(), once)
+			}
+			if twice := printer.PrintFile(again); !bytes.Equal(once, twice) {
+				t.Errorf("formatting is not idempotent:\n--- once\n%s\n--- twice\n%s", once, twice)
+			}
+			if tree(ast) != tree(again) {
+				t.Errorf("formatting changed the AST:\n%s", once)
+			}
+			if !equal(comments(code), comments(once)) {
+				t.Errorf("formatting changed the comments, want %q, got %q", comments(code), comments(once))
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	code := []byte(`// leading comment of the file
+
+/* block comment
+   over two lines */
+func add(a: i32, b: i32) i32 { // trailing comment
+    // comment in a body
+
+    return a + b; // after a statement
+    // last in the body
+}
+// at the end
+`)
+	ast, err := parse(code, "comments.vo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := printer.PrintFile(ast)
+	want := comments(code)
+	if got := comments(out); !equal(want, got) {
+		t.Errorf("want comments %q, got %q in:\n%s", want, got, out)
+	}
+	for _, comment := range want {
+		if !bytes.Contains(out, []byte(comment)) {
+			t.Errorf("%q is not printed verbatim in:\n%s", comment, out)
+		}
+	}
+}
+
+// TestFormatted prints the files in testdata, they are formatted already so the output is the file itself
+func TestFormatted(t *testing.T) {
+	files, _ := filepath.Glob("testdata/*.vo")
+	if len(files) == 0 {
+		t.Fatal("no files in testdata")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			code, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast, perr := parse(code, file)
+			if perr != nil {
+				t.Fatal(perr.Error())
+			}
+			if out := printer.PrintFile(ast); !bytes.Equal(code, out) {
+				t.Errorf("want\n%s\ngot\n%s", code, out)
+			}
+		})
+	}
+}